@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/json"
+	"encoding/xml"
 	"log"
 	"net/http"
 	"os"
@@ -9,11 +9,29 @@ import (
 )
 import "github.com/google/uuid"
 
-type user struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+type greeting struct {
+	XMLName xml.Name `json:"-" yaml:"-" xml:"greeting"`
+	Message string   `json:"message" yaml:"message" xml:",chardata"`
 }
 
+func (g greeting) text() string { return g.Message }
+
+type demoUser struct {
+	XMLName xml.Name `json:"-" yaml:"-" xml:"user"`
+	ID      string   `json:"id" yaml:"id" xml:"id"`
+	Name    string   `json:"name" yaml:"name" xml:"name"`
+}
+
+func (u demoUser) text() string { return u.ID + "\t" + u.Name + "\n" }
+
+type healthStatus struct {
+	XMLName xml.Name `json:"-" yaml:"-" xml:"health"`
+	Status  string   `json:"status" yaml:"status" xml:"status"`
+	Time    string   `json:"time" yaml:"time" xml:"time"`
+}
+
+func (h healthStatus) text() string { return h.Status + " " + h.Time + "\n" }
+
 func main() {
 	port := os.Getenv("APP_PORT")
 	if port == "" {
@@ -21,28 +39,28 @@ func main() {
 	}
 
 	mux := http.NewServeMux()
+	m := newMetrics()
+	mux.Handle("GET /metrics", m)
+	users := &userHandlers{store: newMemoryStore()}
+	users.register(mux)
 
-	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte("Hello, world!"))
-	})
+	mux.HandleFunc("/hello", negotiated(formatText, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, greeting{Message: "Hello, world!"})
+	}))
 
-	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(user{
+	// демо-маршрут из первой версии: ничего не сохраняет, для работы с пользователями есть /users
+	mux.HandleFunc("/user", negotiated(formatJSON, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, demoUser{
 			ID:   uuid.NewString(),
 			Name: "Gopher",
 		})
-	})
+	}))
 
-	//maps.Collect("status", "ok", "time", "<RFC3339>" )
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(
-			map[string]string{"status": "ok", "time": time.Now().UTC().Format(time.RFC3339)},
-		)
-	})
+	mux.HandleFunc("/health", negotiated(formatJSON, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, healthStatus{Status: "ok", Time: time.Now().UTC().Format(time.RFC3339)})
+	}))
 
 	addr := ":" + port
 	log.Printf("Starting on %s ...", addr)
-	log.Fatal(http.ListenAndServe(addr, mux))
+	log.Fatal(http.ListenAndServe(addr, m.middleware(mux)))
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// latencyBuckets — границы гистограммы задержек в секундах, как у клиентов Prometheus по умолчанию.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type requestKey struct {
+	method, route, code string
+}
+
+type routeKey struct {
+	method, route string
+}
+
+type histogram struct {
+	counts []uint64 // накопительно не храним, суммируем при выводе
+	sum    float64
+	count  uint64
+}
+
+// metrics собирает HTTP-метрики и отдаёт их вместе со статистикой рантайма
+// в текстовом формате Prometheus.
+type metrics struct {
+	start    time.Time
+	inFlight atomic.Int64
+
+	mu        sync.Mutex
+	requests  map[requestKey]uint64
+	durations map[routeKey]*histogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		start:     time.Now(),
+		requests:  make(map[requestKey]uint64),
+		durations: make(map[routeKey]*histogram),
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if sr.status == 0 {
+		sr.status = code
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(b)
+}
+
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
+
+// middleware считает запросы. Маршрут берётся из r.Pattern, который ServeMux
+// выставляет в тот же запрос, так что метки не растут от произвольных путей.
+func (m *metrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Add(1)
+		defer m.inFlight.Add(-1)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		m.observe(r.Method, r.Pattern, rec.status, time.Since(start))
+	})
+}
+
+// methodLabel оставляет стандартные методы как есть, а остальные сводит к "other":
+// метод приходит от клиента, и произвольные значения плодили бы новые серии.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+func (m *metrics) observe(method, route string, status int, d time.Duration) {
+	method = methodLabel(method)
+	if route == "" {
+		route = "unmatched"
+	}
+	// "GET /users/{id}" -> "/users/{id}": метод уже есть в отдельной метке
+	if _, path, ok := strings.Cut(route, " "); ok {
+		route = path
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{method, route, strconv.Itoa(status)}]++
+
+	h, ok := m.durations[routeKey{method, route}]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.durations[routeKey{method, route}] = h
+	}
+	sec := d.Seconds()
+	if i, _ := slices.BinarySearch(latencyBuckets, sec); i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += sec
+	h.count++
+}
+
+// ServeHTTP отдаёт /metrics.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	reqKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		reqKeys = append(reqKeys, k)
+	}
+	slices.SortFunc(reqKeys, func(a, b requestKey) int {
+		return cmp.Or(cmp.Compare(a.route, b.route), cmp.Compare(a.method, b.method), cmp.Compare(a.code, b.code))
+	})
+	fmt.Fprintln(w, "# HELP http_requests_total Total number of HTTP requests.")
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	for _, k := range reqKeys {
+		fmt.Fprintf(w, "http_requests_total{method=%s,route=%s,code=%s} %d\n",
+			quote(k.method), quote(k.route), quote(k.code), m.requests[k])
+	}
+
+	routeKeys := make([]routeKey, 0, len(m.durations))
+	for k := range m.durations {
+		routeKeys = append(routeKeys, k)
+	}
+	slices.SortFunc(routeKeys, func(a, b routeKey) int {
+		return cmp.Or(cmp.Compare(a.route, b.route), cmp.Compare(a.method, b.method))
+	})
+	fmt.Fprintln(w, "# HELP http_request_duration_seconds HTTP request latency.")
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds histogram")
+	for _, k := range routeKeys {
+		h := m.durations[k]
+		labels := "method=" + quote(k.method) + ",route=" + quote(k.route)
+		var cumulative uint64
+		for i, le := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=%q} %d\n", labels, formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+	m.mu.Unlock()
+
+	writeGauge(w, "http_requests_in_flight", "Number of HTTP requests being served.", float64(m.inFlight.Load()))
+	m.writeRuntime(w)
+}
+
+func (m *metrics) writeRuntime(w io.Writer) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	fmt.Fprintln(w, "# HELP go_info Information about the Go environment.")
+	fmt.Fprintln(w, "# TYPE go_info gauge")
+	fmt.Fprintf(w, "go_info{version=%s} 1\n", quote(runtime.Version()))
+	writeGauge(w, "go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	writeGauge(w, "go_sched_gomaxprocs_threads", "Current GOMAXPROCS setting.", float64(runtime.GOMAXPROCS(0)))
+	writeGauge(w, "go_memstats_alloc_bytes", "Bytes of allocated heap objects.", float64(ms.Alloc))
+	writeGauge(w, "go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", float64(ms.HeapInuse))
+	writeGauge(w, "go_memstats_heap_objects", "Number of allocated heap objects.", float64(ms.HeapObjects))
+	writeGauge(w, "go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", float64(ms.Sys))
+	writeCounter(w, "go_memstats_mallocs_total", "Total number of heap objects allocated.", float64(ms.Mallocs))
+	writeCounter(w, "go_gc_cycles_total", "Number of completed GC cycles.", float64(ms.NumGC))
+	writeCounter(w, "go_gc_pause_seconds_total", "Total GC stop-the-world pause time.", float64(ms.PauseTotalNs)/1e9)
+	writeGauge(w, "process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(m.start.UnixNano())/1e9)
+}
+
+func writeGauge(w io.Writer, name, help string, v float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatFloat(v))
+}
+
+func writeCounter(w io.Writer, name, help string, v float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %s\n", name, help, name, name, formatFloat(v))
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// quote экранирует значение метки по правилам текстового формата: \, " и перевод строки.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, m *metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	return rec.Body.String()
+}
+
+func wantLines(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, l := range lines {
+		if !strings.Contains(out, "\n"+l+"\n") {
+			t.Errorf("missing line %q", l)
+		}
+	}
+}
+
+func TestMetricsCountsRequestsByRoute(t *testing.T) {
+	m := newMetrics()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	})
+	h := m.middleware(mux)
+	for _, req := range []struct{ method, path string }{
+		{"GET", "/users/1"},
+		{"GET", "/users/2"},
+		{"GET", "/users/missing"},
+		{"GET", "/nope"},
+		{"BREW", "/nope"},
+		{"PROPFIND", "/nope"},
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	out := scrape(t, m)
+	wantLines(t, out,
+		`http_requests_total{method="GET",route="/users/{id}",code="200"} 2`,
+		`http_requests_total{method="GET",route="/users/{id}",code="404"} 1`,
+		`http_requests_total{method="GET",route="unmatched",code="404"} 1`,
+		`http_requests_total{method="other",route="unmatched",code="404"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}"} 3`,
+	)
+	for _, bad := range []string{"/users/1", "BREW", "PROPFIND"} {
+		if strings.Contains(out, `"`+bad+`"`) {
+			t.Errorf("label value %q leaked into the output", bad)
+		}
+	}
+}
+
+func TestMethodLabel(t *testing.T) {
+	for method, want := range map[string]string{
+		"GET": "GET", "HEAD": "HEAD", "PATCH": "PATCH", "OPTIONS": "OPTIONS",
+		"get": "other", "BREW": "other", "": "other",
+	} {
+		if got := methodLabel(method); got != want {
+			t.Errorf("methodLabel(%q) = %q, want %q", method, got, want)
+		}
+	}
+}
+
+func TestMetricsHistogramBuckets(t *testing.T) {
+	m := newMetrics()
+	m.observe("GET", "GET /hello", 200, 30*time.Millisecond)
+	m.observe("GET", "GET /hello", 200, 300*time.Millisecond)
+	m.observe("GET", "GET /hello", 200, time.Minute)
+
+	labels := `method="GET",route="/hello"`
+	wantLines(t, scrape(t, m),
+		`http_request_duration_seconds_bucket{`+labels+`,le="0.025"} 0`,
+		`http_request_duration_seconds_bucket{`+labels+`,le="0.05"} 1`,
+		`http_request_duration_seconds_bucket{`+labels+`,le="0.25"} 1`,
+		`http_request_duration_seconds_bucket{`+labels+`,le="0.5"} 2`,
+		`http_request_duration_seconds_bucket{`+labels+`,le="10"} 2`,
+		`http_request_duration_seconds_bucket{`+labels+`,le="+Inf"} 3`,
+		`http_request_duration_seconds_sum{`+labels+`} 60.33`,
+		`http_request_duration_seconds_count{`+labels+`} 3`,
+	)
+}
+
+var (
+	commentLine = regexp.MustCompile(`^# (HELP|TYPE) ([a-zA-Z_:][a-zA-Z0-9_:]*) (.+)$`)
+	sampleLine  = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{([a-zA-Z_][a-zA-Z0-9_]*="([^"\\]|\\.)*",?)*\})? (\S+)$`)
+)
+
+// TestMetricsExpositionFormat проверяет, что каждая строка разбирается по правилам
+// текстового формата и у каждой серии есть HELP и TYPE до первого значения.
+func TestMetricsExpositionFormat(t *testing.T) {
+	m := newMetrics()
+	m.observe("GET", "GET /users/{id}", 200, time.Millisecond)
+	m.observe("POST", `/odd "route"`+"\n", 500, time.Millisecond)
+
+	types := map[string]string{}
+	sc := bufio.NewScanner(strings.NewReader(scrape(t, m)))
+	for sc.Scan() {
+		line := sc.Text()
+		if c := commentLine.FindStringSubmatch(line); c != nil {
+			if c[1] == "TYPE" {
+				types[c[2]] = c[3]
+			}
+			continue
+		}
+		s := sampleLine.FindStringSubmatch(line)
+		if s == nil {
+			t.Errorf("malformed line %q", line)
+			continue
+		}
+		family := s[1]
+		if types[family] == "" {
+			for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+				if base, ok := strings.CutSuffix(family, suffix); ok && types[base] == "histogram" {
+					family = base
+				}
+			}
+		}
+		if types[family] == "" {
+			t.Errorf("sample %q has no TYPE before it", line)
+		}
+	}
+	for name, typ := range map[string]string{
+		"http_requests_total":           "counter",
+		"http_request_duration_seconds": "histogram",
+		"http_requests_in_flight":       "gauge",
+		"go_goroutines":                 "gauge",
+	} {
+		if types[name] != typ {
+			t.Errorf("TYPE %s = %q, want %q", name, types[name], typ)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// format — один из поддерживаемых форматов ответа. Первый тип в types —
+// каноничный, он уходит в Content-Type; остальные принимаются в Accept как синонимы.
+type format struct {
+	name   string
+	types  []string
+	encode func(w io.Writer, v any) error
+}
+
+var (
+	formatJSON = &format{name: "json", types: []string{"application/json"}, encode: func(w io.Writer, v any) error {
+		return json.NewEncoder(w).Encode(v)
+	}}
+	formatXML = &format{name: "xml", types: []string{"application/xml", "text/xml"}, encode: func(w io.Writer, v any) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		return xml.NewEncoder(w).Encode(v)
+	}}
+	formatYAML = &format{name: "yaml", types: []string{"application/yaml", "application/x-yaml", "text/yaml"}, encode: func(w io.Writer, v any) error {
+		return yaml.NewEncoder(w).Encode(v)
+	}}
+	formatMsgpack = &format{name: "msgpack", types: []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}, encode: func(w io.Writer, v any) error {
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		return enc.Encode(v)
+	}}
+	formatText = &format{name: "text", types: []string{"text/plain"}, encode: func(w io.Writer, v any) error {
+		if t, ok := v.(texter); ok {
+			_, err := io.WriteString(w, t.text())
+			return err
+		}
+		_, err := fmt.Fprintln(w, v)
+		return err
+	}}
+
+	// formats в порядке предпочтения сервера: он решает при равных q.
+	formats = []*format{formatJSON, formatXML, formatYAML, formatMsgpack, formatText}
+)
+
+// texter — представление значения для text/plain.
+type texter interface {
+	text() string
+}
+
+func (f *format) contentType() string {
+	if strings.HasPrefix(f.types[0], "text/") {
+		return f.types[0] + "; charset=utf-8"
+	}
+	return f.types[0]
+}
+
+type formatKey struct{}
+
+// negotiated выбирает формат ответа по ?format= или Accept до вызова обработчика,
+// чтобы 406 возвращался раньше, чем запрос что-то изменит. def — формат маршрута
+// для клиентов, которым подходит что угодно.
+func negotiated(def *format, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		f, ok := negotiate(r, def)
+		if !ok {
+			writeNotAcceptable(w)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), formatKey{}, f)))
+	}
+}
+
+func negotiate(r *http.Request, def *format) (*format, bool) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, f := range formats {
+			if f.name == name {
+				return f, true
+			}
+		}
+		return nil, false
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return def, true
+	}
+	ranges := parseAccept(accept)
+
+	var best *format
+	bestQ := 0.0
+	for _, f := range append([]*format{def}, formats...) {
+		if q := f.quality(ranges); q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best, best != nil
+}
+
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+// parseAccept разбирает Accept; диапазоны с ошибками пропускаются.
+func parseAccept(header string) []mediaRange {
+	var out []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, sub, ok := strings.Cut(mt, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		out = append(out, mediaRange{typ: typ, sub: sub, q: q})
+	}
+	return out
+}
+
+// quality — q самого конкретного диапазона, подходящего под формат:
+// точный тип важнее "type/*", а тот важнее "*/*".
+func (f *format) quality(ranges []mediaRange) float64 {
+	q, specificity := 0.0, -1
+	for _, t := range f.types {
+		typ, sub, _ := strings.Cut(t, "/")
+		for _, mr := range ranges {
+			s := -1
+			switch {
+			case mr.typ == typ && mr.sub == sub:
+				s = 2
+			case mr.typ == typ && mr.sub == "*":
+				s = 1
+			case mr.typ == "*" && mr.sub == "*":
+				s = 0
+			}
+			if s > specificity || (s == specificity && s >= 0 && mr.q > q) {
+				q, specificity = mr.q, s
+			}
+		}
+	}
+	return q
+}
+
+// respond кодирует v в формате, выбранном negotiated (JSON, если маршрут не обёрнут).
+func respond(w http.ResponseWriter, r *http.Request, code int, v any) {
+	f, ok := r.Context().Value(formatKey{}).(*format)
+	if !ok {
+		f = formatJSON
+	}
+	w.Header().Set("Content-Type", f.contentType())
+	w.WriteHeader(code)
+	_ = f.encode(w, v)
+}
+
+func writeNotAcceptable(w http.ResponseWriter) {
+	supported := make([]string, 0, len(formats))
+	for _, f := range formats {
+		supported = append(supported, f.types[0])
+	}
+	w.Header().Set("Content-Type", formatJSON.contentType())
+	w.WriteHeader(http.StatusNotAcceptable)
+	_ = json.NewEncoder(w).Encode(struct {
+		Error     string   `json:"error"`
+		Supported []string `json:"supported"`
+	}{"none of the requested media types is supported", supported})
+}
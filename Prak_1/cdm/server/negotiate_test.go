@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiatedHandlers(t *testing.T) {
+	hello := negotiated(formatText, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, greeting{Message: "Hello, world!"})
+	})
+	health := negotiated(formatJSON, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, healthStatus{Status: "ok"})
+	})
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		accept  string
+		code    int
+		ctype   string
+	}{
+		{"no accept uses route default", hello, "/hello", "", http.StatusOK, "text/plain; charset=utf-8"},
+		{"no accept json route", health, "/health", "", http.StatusOK, "application/json"},
+		{"exact type", hello, "/hello", "application/xml", http.StatusOK, "application/xml"},
+		{"synonym gets canonical type", hello, "/hello", "text/yaml", http.StatusOK, "application/yaml"},
+		{"any type uses route default", health, "/health", "*/*", http.StatusOK, "application/json"},
+		{"type wildcard", hello, "/hello", "application/*", http.StatusOK, "application/json"},
+		{"type wildcard matches synonyms", health, "/health", "text/*", http.StatusOK, "application/xml"},
+		{"highest q wins", health, "/health", "application/json;q=0.2, application/xml;q=0.8", http.StatusOK, "application/xml"},
+		{"specific range beats wildcard", health, "/health", "*/*;q=0.9, application/json;q=0.1, application/yaml", http.StatusOK, "application/yaml"},
+		{"q=0 excludes a type", hello, "/hello", "text/plain;q=0, */*;q=0.5", http.StatusOK, "application/json"},
+		{"server order breaks ties", hello, "/hello", "application/msgpack, application/xml", http.StatusOK, "application/xml"},
+		{"browser accept", hello, "/hello", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", http.StatusOK, "application/xml"},
+		{"malformed ranges are skipped", health, "/health", "garbage, application/xml;q=2, application/yaml;q=0.5", http.StatusOK, "application/yaml"},
+		{"format param wins over accept", health, "/health?format=msgpack", "application/xml", http.StatusOK, "application/msgpack"},
+		{"unsupported type", health, "/health", "text/html", http.StatusNotAcceptable, "application/json"},
+		{"everything excluded", health, "/health", "*/*;q=0", http.StatusNotAcceptable, "application/json"},
+		{"unknown format param", health, "/health?format=csv", "", http.StatusNotAcceptable, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			tt.handler(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.ctype {
+				t.Errorf("Content-Type = %q, want %q", got, tt.ctype)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Vary = %q, want Accept", got)
+			}
+		})
+	}
+}
+
+func TestNotAcceptableListsSupportedTypes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Accept", "image/png")
+	negotiated(formatJSON, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler called for an unacceptable request")
+	})(rec, req)
+	for _, typ := range []string{"application/json", "application/xml", "application/yaml", "application/msgpack", "text/plain"} {
+		if !strings.Contains(rec.Body.String(), `"`+typ+`"`) {
+			t.Errorf("406 body %s does not list %s", rec.Body, typ)
+		}
+	}
+}
+
+// 406 возвращается до обработчика, поэтому POST не создаёт пользователя.
+func TestNotAcceptableDoesNotMutate(t *testing.T) {
+	store := newMemoryStore()
+	mux := http.NewServeMux()
+	(&userHandlers{store: store}).register(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name": "Gopher"}`))
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("status = %d, want 406", rec.Code)
+	}
+	if _, total := store.List(userFilter{}); total != 0 {
+		t.Errorf("store has %d users after a 406", total)
+	}
+}
+
+func TestRespondEncodesNegotiatedFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"json", `{"message":"hi"}`},
+		{"xml", `<greeting>hi</greeting>`},
+		{"yaml", "message: hi\n"},
+		{"text", "hi"},
+	}
+	h := negotiated(formatJSON, func(w http.ResponseWriter, r *http.Request) {
+		respond(w, r, http.StatusOK, greeting{Message: "hi"})
+	})
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h(rec, httptest.NewRequest(http.MethodGet, "/?format="+tt.format, nil))
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userFilter — параметры выборки: Name ищет подстроку без учёта регистра.
+type userFilter struct {
+	Name   string
+	Limit  int
+	Offset int
+}
+
+// userStore — хранилище пользователей; реализация должна быть безопасна для конкурентного доступа.
+type userStore interface {
+	Create(name string) (user, error)
+	Get(id string) (user, error)
+	List(f userFilter) ([]user, int)
+	Update(id, name string) (user, error)
+	Delete(id string) error
+}
+
+// memoryStore хранит пользователей в памяти. UUIDv7 растут со временем,
+// поэтому сортировка по id совпадает с порядком создания.
+type memoryStore struct {
+	mu    sync.RWMutex
+	users map[string]user
+}
+
+var _ userStore = (*memoryStore)(nil)
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{users: make(map[string]user)}
+}
+
+func (s *memoryStore) Create(name string) (user, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return user{}, err
+	}
+	now := time.Now().UTC()
+	u := user{ID: id.String(), Name: name, CreatedAt: now, UpdatedAt: now}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[u.ID] = u
+	return u, nil
+}
+
+func (s *memoryStore) Get(id string) (user, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[id]
+	if !ok {
+		return user{}, errNotFound
+	}
+	return u, nil
+}
+
+// List возвращает страницу пользователей и общее число подходящих под фильтр.
+func (s *memoryStore) List(f userFilter) ([]user, int) {
+	needle := strings.ToLower(f.Name)
+
+	s.mu.RLock()
+	out := make([]user, 0, len(s.users))
+	for _, u := range s.users {
+		if needle == "" || strings.Contains(strings.ToLower(u.Name), needle) {
+			out = append(out, u)
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(out, func(a, b user) int { return strings.Compare(a.ID, b.ID) })
+	total := len(out)
+	if f.Offset >= total {
+		return []user{}, total
+	}
+	out = out[f.Offset:]
+	if f.Limit > 0 && f.Limit < len(out) {
+		out = out[:f.Limit]
+	}
+	return out, total
+}
+
+func (s *memoryStore) Update(id, name string) (user, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return user{}, errNotFound
+	}
+	u.Name = name
+	u.UpdatedAt = time.Now().UTC()
+	s.users[id] = u
+	return u, nil
+}
+
+func (s *memoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[id]; !ok {
+		return errNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMemoryStoreCRUD(t *testing.T) {
+	s := newMemoryStore()
+	u, err := s.Create("Gopher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID == "" || u.CreatedAt.IsZero() || !u.CreatedAt.Equal(u.UpdatedAt) {
+		t.Fatalf("created user = %+v", u)
+	}
+
+	got, err := s.Get(u.ID)
+	if err != nil || got != u {
+		t.Fatalf("Get = %+v, %v; want %+v", got, err, u)
+	}
+
+	upd, err := s.Update(u.ID, "Rob Pike")
+	if err != nil || upd.Name != "Rob Pike" || !upd.CreatedAt.Equal(u.CreatedAt) || upd.UpdatedAt.Before(u.UpdatedAt) {
+		t.Fatalf("Update = %+v, %v", upd, err)
+	}
+	if got, _ := s.Get(u.ID); got.Name != "Rob Pike" {
+		t.Errorf("Get after update = %+v", got)
+	}
+
+	if err := s.Delete(u.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(u.ID); !errors.Is(err, errNotFound) {
+		t.Errorf("Get after delete: %v, want errNotFound", err)
+	}
+}
+
+func TestMemoryStoreNotFound(t *testing.T) {
+	s := newMemoryStore()
+	const id = "0190b6f2-0000-7000-8000-000000000000"
+	if _, err := s.Get(id); !errors.Is(err, errNotFound) {
+		t.Errorf("Get: %v", err)
+	}
+	if _, err := s.Update(id, "Nobody"); !errors.Is(err, errNotFound) {
+		t.Errorf("Update: %v", err)
+	}
+	if err := s.Delete(id); !errors.Is(err, errNotFound) {
+		t.Errorf("Delete: %v", err)
+	}
+	if _, total := s.List(userFilter{}); total != 0 {
+		t.Errorf("Update of a missing user created %d users", total)
+	}
+}
+
+func TestMemoryStoreList(t *testing.T) {
+	s := newMemoryStore()
+	var names []string
+	for _, name := range []string{"Alice", "bob", "Alicia", "Carol", "BOBBY"} {
+		if _, err := s.Create(name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	tests := []struct {
+		filter userFilter
+		names  []string
+		total  int
+	}{
+		{userFilter{}, names, 5},
+		{userFilter{Name: "ali"}, []string{"Alice", "Alicia"}, 2},
+		{userFilter{Name: "Bob"}, []string{"bob", "BOBBY"}, 2},
+		{userFilter{Name: "zed"}, []string{}, 0},
+		{userFilter{Limit: 2}, []string{"Alice", "bob"}, 5},
+		{userFilter{Limit: 2, Offset: 3}, []string{"Carol", "BOBBY"}, 5},
+		{userFilter{Limit: 2, Offset: 4}, []string{"BOBBY"}, 5},
+		{userFilter{Offset: 5}, []string{}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%+v", tt.filter), func(t *testing.T) {
+			items, total := s.List(tt.filter)
+			got := []string{}
+			for _, u := range items {
+				got = append(got, u.Name)
+			}
+			if !slices.Equal(got, tt.names) || total != tt.total {
+				t.Errorf("got %v (total %d), want %v (total %d)", got, total, tt.names, tt.total)
+			}
+		})
+	}
+}
+
+// Запускать с -race: хранилище используется из обработчиков параллельно.
+func TestMemoryStoreConcurrentAccess(t *testing.T) {
+	s := newMemoryStore()
+	const workers, perWorker = 8, 50
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			for i := range perWorker {
+				u, err := s.Create(fmt.Sprintf("user %d %d", w, i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := s.Update(u.ID, u.Name+" upd"); err != nil {
+					t.Error(err)
+				}
+				s.List(userFilter{Name: "upd", Limit: 10})
+				if i%2 == 0 {
+					if err := s.Delete(u.ID); err != nil {
+						t.Error(err)
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	items, total := s.List(userFilter{})
+	if want := workers * perWorker / 2; total != want {
+		t.Errorf("total = %d, want %d", total, want)
+	}
+	seen := map[string]bool{}
+	for _, u := range items {
+		if seen[u.ID] {
+			t.Errorf("duplicate id %s", u.ID)
+		}
+		seen[u.ID] = true
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	nameMinLen = 2
+	nameMaxLen = 64
+)
+
+var (
+	errNotFound    = errors.New("user not found")
+	errInvalidName = errors.New("name must be 2-64 characters: letters, digits, spaces, '-', '_', '.' or apostrophe")
+)
+
+type user struct {
+	XMLName   xml.Name  `json:"-" yaml:"-" xml:"user"`
+	ID        string    `json:"id" yaml:"id" xml:"id"`
+	Name      string    `json:"name" yaml:"name" xml:"name"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at" xml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at" xml:"updated_at"`
+}
+
+func (u user) text() string {
+	return u.ID + "\t" + u.Name + "\n"
+}
+
+// normalizeName убирает лишние пробелы и проверяет длину и допустимые символы.
+func normalizeName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if n := utf8.RuneCountInString(name); n < nameMinLen || n > nameMaxLen {
+		return "", errInvalidName
+	}
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(" -_.'", r) {
+			continue
+		}
+		return "", errInvalidName
+	}
+	return name, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+	maxBodyBytes     = 1 << 20
+)
+
+type userHandlers struct {
+	store userStore
+}
+
+type userRequest struct {
+	Name string `json:"name"`
+}
+
+type userList struct {
+	XMLName xml.Name `json:"-" yaml:"-" xml:"users"`
+	Items   []user   `json:"items" yaml:"items" xml:"user"`
+	Total   int      `json:"total" yaml:"total" xml:"total,attr"`
+}
+
+func (l userList) text() string {
+	var b strings.Builder
+	for _, u := range l.Items {
+		b.WriteString(u.text())
+	}
+	fmt.Fprintf(&b, "total: %d\n", l.Total)
+	return b.String()
+}
+
+// apiError — тело ответа с ошибкой, в JSON это {"error": "..."}.
+type apiError struct {
+	XMLName xml.Name `json:"-" yaml:"-" xml:"error"`
+	Message string   `json:"error" yaml:"error" xml:"message"`
+}
+
+func (e apiError) text() string {
+	return "error: " + e.Message + "\n"
+}
+
+func (h *userHandlers) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /users", negotiated(formatJSON, h.list))
+	mux.HandleFunc("POST /users", negotiated(formatJSON, h.create))
+	mux.HandleFunc("GET /users/{id}", negotiated(formatJSON, h.get))
+	mux.HandleFunc("PUT /users/{id}", negotiated(formatJSON, h.update))
+	mux.HandleFunc("DELETE /users/{id}", negotiated(formatJSON, h.delete))
+}
+
+// GET /users?name=go&limit=50&offset=0
+func (h *userHandlers) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, err := intParam(q.Get("limit"), defaultListLimit)
+	if err != nil || limit < 1 || limit > maxListLimit {
+		writeError(w, r, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+		return
+	}
+	offset, err := intParam(q.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		writeError(w, r, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	items, total := h.store.List(userFilter{Name: q.Get("name"), Limit: limit, Offset: offset})
+	respond(w, r, http.StatusOK, userList{Items: items, Total: total})
+}
+
+func (h *userHandlers) create(w http.ResponseWriter, r *http.Request) {
+	name, ok := decodeName(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.store.Create(name)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to create user")
+		return
+	}
+	w.Header().Set("Location", "/users/"+u.ID)
+	respond(w, r, http.StatusCreated, u)
+}
+
+func (h *userHandlers) get(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.store.Get(id)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	respond(w, r, http.StatusOK, u)
+}
+
+func (h *userHandlers) update(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	name, ok := decodeName(w, r)
+	if !ok {
+		return
+	}
+	u, err := h.store.Update(id, name)
+	if err != nil {
+		storeError(w, r, err)
+		return
+	}
+	respond(w, r, http.StatusOK, u)
+}
+
+func (h *userHandlers) delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.Delete(id); err != nil {
+		storeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeName читает тело {"name": "..."} и проверяет имя; при ошибке ответ уже записан.
+func decodeName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req userRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid json: "+err.Error())
+		return "", false
+	}
+	name, err := normalizeName(req.Name)
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return "", false
+	}
+	return name, true
+}
+
+// pathID приводит id из пути к каноничному виду UUID.
+func pathID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "id must be a UUID")
+		return "", false
+	}
+	return id.String(), true
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func storeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errNotFound) {
+		writeError(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, "internal error")
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, code int, msg string) {
+	respond(w, r, code, apiError{Message: msg})
+}
@@ -1,9 +1,41 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
 	"prak2/internal/app"
+	"prak2/internal/config"
+)
+
+// Коды выхода: 0 — штатная остановка, 1 — сбой во время работы, 2 — неверная конфигурация.
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitConfig = 2
 )
 
 func main() {
-	app.Run()
+	os.Exit(run())
+}
+
+func run() int {
+	cfg, err := config.Load(os.Args[1:], os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return exitConfig
+	}
+
+	if err := app.Run(context.Background(), cfg); err != nil {
+		slog.Error("app failed", "err", err)
+		return exitFailed
+	}
+	return exitOK
 }
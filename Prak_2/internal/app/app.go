@@ -1,54 +1,164 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"prak2/internal/app/handlers"
+	"prak2/internal/config"
+	"runtime"
+	"syscall"
 
-	"prak2/utils"
+	"prak2/pkg/health"
+	"prak2/pkg/httpkit"
+	"prak2/pkg/logging"
 )
 
-func withRequestID(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id := r.Header.Get("X-Request-Id")
-		if id == "" {
-			id = utils.NewID16()
-		}
-		w.Header().Set("X-Request-Id", id)
-		next.ServeHTTP(w, r)
+// Версия и коммит сборки, задаются через
+// -ldflags "-X prak2/internal/app.Version=1.2.3 -X prak2/internal/app.Commit=abc123".
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// maxGoroutines — порог некритичной проверки "goroutines": больше — вероятна утечка.
+const maxGoroutines = 10000
+
+// App — HTTP-сервис с набором компонентов, зарегистрированных в Lifecycle.
+type App struct {
+	cfg       config.Config
+	logger    *slog.Logger
+	server    *http.Server
+	lifecycle Lifecycle
+	health    *health.Registry
+	serveErr  chan error
+}
+
+// New собирает приложение; компоненты регистрируют свои хуки через Lifecycle().
+func New(cfg config.Config) (*App, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	level, _ := logging.ParseLevel(cfg.LogLevel)
+	format, _ := logging.ParseFormat(cfg.LogFormat)
+	logger := logging.New(logging.Options{Level: level, Format: format})
+	slog.SetDefault(logger)
+
+	a := &App{
+		cfg:      cfg,
+		logger:   logger,
+		health:   health.NewRegistry(health.ReadBuildInfo(Version, Commit)),
+		serveErr: make(chan error, 1),
+	}
+	_ = a.health.Register(health.Check{
+		Name: "goroutines",
+		Fn: func(context.Context) error {
+			if n := runtime.NumGoroutine(); n > maxGoroutines {
+				return fmt.Errorf("%d goroutines running, limit %d", n, maxGoroutines)
+			}
+			return nil
+		},
 	})
+	a.server = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           a.routes(),
+		ReadTimeout:       cfg.ReadTimeout.Std(),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout.Std(),
+		WriteTimeout:      cfg.WriteTimeout.Std(),
+		IdleTimeout:       cfg.IdleTimeout.Std(),
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+	}
+	a.lifecycle.Append(Hook{Name: "http", OnStart: a.startHTTP, OnStop: a.server.Shutdown})
+	return a, nil
+}
+
+func (a *App) Lifecycle() *Lifecycle {
+	return &a.lifecycle
+}
+
+// Health — реестр проверок, в который компоненты добавляют проверки своих зависимостей.
+func (a *App) Health() *health.Registry {
+	return a.health
 }
 
-func Run() {
+func (a *App) routes() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/ping", handlers.Ping)
+	mux.HandleFunc("/ping", handlers.Ping(a.health))
 
 	// Корневой маршрут
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		utils.LogRequest(r)
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		fmt.Fprintln(w, "Hello, Go project structure!")
 	})
 
 	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
-		utils.LogRequest(r)
-		utils.WriteErr(w, http.StatusBadRequest, "bad_request_example")
+		logging.Warn(r.Context(), "fail endpoint called")
+		httpkit.Error(w, r, http.StatusBadRequest, "bad_request_example")
 	})
 
-	//// Пример JSON-ручки: /ping
-	//mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
-	//	utils.LogRequest(r)
-	//	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	//	_ = json.NewEncoder(w).Encode(handlers.PingResp{
-	//		Status: "ok",
-	//		Time:   time.Now().UTC().Format(time.RFC3339),
-	//	})
-	//})
+	return httpkit.NewChain(
+		httpkit.RequestID,
+		httpkit.AccessLog(a.logger),
+		httpkit.Recover,
+	).Then(mux)
+}
 
-	handler := withRequestID(mux)
+// startHTTP занимает порт синхронно, чтобы ошибка вроде "address already in use"
+// вернулась из Start, а обслуживание запросов идёт в фоне.
+func (a *App) startHTTP(ctx context.Context) error {
+	ln, err := net.Listen("tcp", a.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	a.logger.InfoContext(ctx, "server is starting", "addr", ln.Addr().String())
+	go func() {
+		if err := a.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.serveErr <- err
+		}
+	}()
+	return nil
+}
+
+// Run запускает компоненты и ждёт SIGINT/SIGTERM, отмены ctx или падения сервера,
+// после чего останавливает всё в пределах ShutdownTimeout.
+// Возвращает ошибку, если запуск, работа или остановка прошли неуспешно.
+func (a *App) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.lifecycle.Start(ctx); err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutdown signal received")
+	case err := <-a.serveErr:
+		runErr = fmt.Errorf("http server: %w", err)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.cfg.ShutdownTimeout.Std())
+	defer cancel()
+	if err := a.lifecycle.Stop(stopCtx); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+	if runErr == nil {
+		a.logger.Info("server stopped gracefully")
+	}
+	return runErr
+}
 
-	utils.LogInfo("Server is starting on :8080")
-	if err := http.ListenAndServe(":8080", handler); err != nil {
-		utils.LogError("server error: " + err.Error())
+// Run — короткая запись для New(cfg) и App.Run.
+func Run(ctx context.Context, cfg config.Config) error {
+	a, err := New(cfg)
+	if err != nil {
+		return err
 	}
+	return a.Run(ctx)
 }
@@ -1,23 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
-	"time"
 
-	"prak2/utils"
+	"prak2/pkg/health"
+	"prak2/pkg/httpkit"
 )
 
-type pingResp struct {
-	Status string `json:"status"`
-	Time   string `json:"time"`
-}
-
-func Ping(w http.ResponseWriter, r *http.Request) {
-	utils.LogRequest(r)
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	_ = json.NewEncoder(w).Encode(pingResp{
-		Status: "ok",
-		Time:   time.Now().UTC().Format(time.RFC3339),
-	})
+// Ping выполняет проверки из реестра и отдаёт отчёт: 200, если сервис
+// работоспособен (в том числе degraded), и 503, если упала критичная проверка.
+func Ping(reg *health.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rep := reg.Run(r.Context())
+		code := http.StatusOK
+		if rep.Status == health.StatusFail {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		httpkit.WriteJSON(w, code, rep)
+	}
 }
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Hook — функции запуска и остановки компонента; любая из них может быть nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle хранит хуки компонентов. Запуск идёт в порядке регистрации,
+// остановка — в обратном, чтобы зависимые компоненты гасли раньше своих зависимостей.
+type Lifecycle struct {
+	hooks   []Hook
+	started int
+}
+
+func (l *Lifecycle) Append(h Hook) {
+	l.hooks = append(l.hooks, h)
+}
+
+// Start запускает хуки по очереди. Если один из них падает, уже запущенные
+// компоненты останавливаются, а ошибка возвращается.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, h := range l.hooks {
+		if h.OnStart != nil {
+			slog.DebugContext(ctx, "starting component", "component", h.Name)
+			if err := h.OnStart(ctx); err != nil {
+				startErr := fmt.Errorf("start %s: %w", h.Name, err)
+				return errors.Join(startErr, l.Stop(ctx))
+			}
+		}
+		l.started++
+	}
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке и собирает все ошибки.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		slog.DebugContext(ctx, "stopping component", "component", h.Name)
+		if err := h.OnStop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", h.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLifecycle_StopsInReverseAndRollsBack(t *testing.T) {
+	var calls []string
+	hook := func(name string, failStart bool) Hook {
+		return Hook{
+			Name: name,
+			OnStart: func(context.Context) error {
+				calls = append(calls, "start "+name)
+				if failStart {
+					return errors.New("boom")
+				}
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				calls = append(calls, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	var l Lifecycle
+	l.Append(hook("db", false))
+	l.Append(hook("cache", false))
+	l.Append(hook("http", true))
+
+	err := l.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start http") {
+		t.Fatalf("expected start error, got %v", err)
+	}
+
+	want := "start db,start cache,start http,stop cache,stop db"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	if err := l.Stop(context.Background()); err != nil || len(calls) != 5 {
+		t.Fatalf("second stop must be a no-op, got err=%v calls=%v", err, calls)
+	}
+}
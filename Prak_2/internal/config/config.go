@@ -0,0 +1,217 @@
+// Package config собирает настройки приложения из значений по умолчанию,
+// необязательного JSON-файла, переменных окружения и флагов (в порядке возрастания приоритета).
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"prak2/pkg/logging"
+)
+
+type Config struct {
+	Addr              string   `json:"addr"`
+	ReadTimeout       Duration `json:"read_timeout"`
+	ReadHeaderTimeout Duration `json:"read_header_timeout"`
+	WriteTimeout      Duration `json:"write_timeout"`
+	IdleTimeout       Duration `json:"idle_timeout"`
+	ShutdownTimeout   Duration `json:"shutdown_timeout"`
+	LogLevel          string   `json:"log_level"`
+	LogFormat         string   `json:"log_format"`
+}
+
+// Duration — time.Duration, которая в JSON записывается строкой вида "15s".
+type Duration time.Duration
+
+func (d Duration) Std() time.Duration { return time.Duration(d) }
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"15s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Set и String позволяют использовать Duration как flag.Value.
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func Default() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadTimeout:       Duration(10 * time.Second),
+		ReadHeaderTimeout: Duration(5 * time.Second),
+		WriteTimeout:      Duration(15 * time.Second),
+		IdleTimeout:       Duration(60 * time.Second),
+		ShutdownTimeout:   Duration(15 * time.Second),
+		LogLevel:          "info",
+		LogFormat:         "text",
+	}
+}
+
+// Load читает настройки: файл берётся из флага -config или APP_CONFIG.
+// getenv обычно os.Getenv; ошибка flag.ErrHelp означает, что была запрошена справка.
+func Load(args []string, getenv func(string) string, usage io.Writer) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	fs.SetOutput(usage)
+	var (
+		path  = fs.String("config", "", "path to JSON config file (env APP_CONFIG)")
+		flags Config
+	)
+	fs.StringVar(&flags.Addr, "addr", "", "listen address (env APP_ADDR)")
+	fs.Var(&flags.ReadTimeout, "read-timeout", "server read timeout (env APP_READ_TIMEOUT)")
+	fs.Var(&flags.ReadHeaderTimeout, "read-header-timeout", "server read header timeout (env APP_READ_HEADER_TIMEOUT)")
+	fs.Var(&flags.WriteTimeout, "write-timeout", "server write timeout (env APP_WRITE_TIMEOUT)")
+	fs.Var(&flags.IdleTimeout, "idle-timeout", "keep-alive idle timeout (env APP_IDLE_TIMEOUT)")
+	fs.Var(&flags.ShutdownTimeout, "shutdown-timeout", "graceful shutdown deadline (env APP_SHUTDOWN_TIMEOUT)")
+	fs.StringVar(&flags.LogLevel, "log-level", "", "debug, info, warn or error (env LOG_LEVEL)")
+	fs.StringVar(&flags.LogFormat, "log-format", "", "text or json (env LOG_FORMAT)")
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *path == "" {
+		*path = getenv("APP_CONFIG")
+	}
+	if *path != "" {
+		if err := loadFile(*path, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := applyEnv(&cfg, getenv); err != nil {
+		return cfg, err
+	}
+
+	// флаги перекрывают всё остальное, но только если их явно передали
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Addr = flags.Addr
+		case "read-timeout":
+			cfg.ReadTimeout = flags.ReadTimeout
+		case "read-header-timeout":
+			cfg.ReadHeaderTimeout = flags.ReadHeaderTimeout
+		case "write-timeout":
+			cfg.WriteTimeout = flags.WriteTimeout
+		case "idle-timeout":
+			cfg.IdleTimeout = flags.IdleTimeout
+		case "shutdown-timeout":
+			cfg.ShutdownTimeout = flags.ShutdownTimeout
+		case "log-level":
+			cfg.LogLevel = flags.LogLevel
+		case "log-format":
+			cfg.LogFormat = flags.LogFormat
+		}
+	})
+
+	return cfg, cfg.Validate()
+}
+
+func loadFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, getenv func(string) string) error {
+	if v := getenv("APP_ADDR"); v != "" {
+		cfg.Addr = v
+	} else if port := getenv("PORT"); port != "" {
+		cfg.Addr = ":" + port
+	}
+	durations := []struct {
+		env string
+		dst *Duration
+	}{
+		{"APP_READ_TIMEOUT", &cfg.ReadTimeout},
+		{"APP_READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout},
+		{"APP_WRITE_TIMEOUT", &cfg.WriteTimeout},
+		{"APP_IDLE_TIMEOUT", &cfg.IdleTimeout},
+		{"APP_SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout},
+	}
+	for _, d := range durations {
+		if v := getenv(d.env); v != "" {
+			if err := d.dst.Set(v); err != nil {
+				return fmt.Errorf("%s: %w", d.env, err)
+			}
+		}
+	}
+	if v := getenv("LOG_LEVEL"); v != "" {
+		cfg.LogLevel = v
+	}
+	if v := getenv("LOG_FORMAT"); v != "" {
+		cfg.LogFormat = v
+	}
+	return nil
+}
+
+// Validate проверяет все поля и возвращает все найденные ошибки разом.
+func (c Config) Validate() error {
+	var errs []error
+	if _, port, err := net.SplitHostPort(c.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("addr %q: %w", c.Addr, err))
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		errs = append(errs, fmt.Errorf("addr %q: invalid port", c.Addr))
+	}
+
+	timeouts := []struct {
+		name string
+		v    Duration
+	}{
+		{"read_timeout", c.ReadTimeout},
+		{"read_header_timeout", c.ReadHeaderTimeout},
+		{"write_timeout", c.WriteTimeout},
+		{"idle_timeout", c.IdleTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+	}
+	for _, t := range timeouts {
+		if t.v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", t.name, t.v))
+		}
+	}
+
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := logging.ParseFormat(c.LogFormat); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
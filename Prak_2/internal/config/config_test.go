@@ -0,0 +1,56 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	if err := os.WriteFile(path, []byte(`{"addr":":9000","write_timeout":"20s","log_level":"debug"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{
+		"APP_CONFIG":       path,
+		"APP_ADDR":         ":9100",
+		"APP_IDLE_TIMEOUT": "2m",
+	}
+
+	cfg, err := Load([]string{"-addr", ":9200"}, func(k string) string { return env[k] }, io.Discard)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if cfg.Addr != ":9200" {
+		t.Errorf("flag should win, got addr %q", cfg.Addr)
+	}
+	if cfg.WriteTimeout.Std() != 20*time.Second || cfg.LogLevel != "debug" {
+		t.Errorf("file values not applied: %+v", cfg)
+	}
+	if cfg.IdleTimeout.Std() != 2*time.Minute {
+		t.Errorf("env value not applied, idle timeout %s", cfg.IdleTimeout)
+	}
+	if cfg.ReadTimeout != Default().ReadTimeout {
+		t.Errorf("default lost, read timeout %s", cfg.ReadTimeout)
+	}
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	env := map[string]string{"LOG_FORMAT": "xml"}
+	_, err := Load([]string{"-shutdown-timeout", "0s"}, func(k string) string { return env[k] }, io.Discard)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestLoad_UnknownFileField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	_ = os.WriteFile(path, []byte(`{"adress":":9000"}`), 0o644)
+
+	if _, err := Load([]string{"-config", path}, func(string) string { return "" }, io.Discard); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
@@ -0,0 +1,202 @@
+// Package health — реестр проверок состояния сервиса и его зависимостей.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout применяется к проверкам без собственного таймаута.
+const DefaultTimeout = 2 * time.Second
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFail     Status = "fail"
+	StatusDegraded Status = "degraded" // упала только некритичная проверка
+)
+
+// Check — именованная проверка. Critical-проверки при падении делают весь сервис
+// неработоспособным, остальные только переводят его в degraded.
+type Check struct {
+	Name     string
+	Timeout  time.Duration
+	Critical bool
+	Fn       func(ctx context.Context) error
+}
+
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo дополняет переданные версию и коммит (обычно из -ldflags)
+// сведениями из бинарника: ревизией VCS и версией Go.
+func ReadBuildInfo(version, commit string) BuildInfo {
+	bi := BuildInfo{Version: version, Commit: commit, GoVersion: runtime.Version()}
+	if bi.Version == "" {
+		bi.Version = "dev"
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && bi.Commit == "" {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				bi.Commit = s.Value
+			}
+		}
+	}
+	return bi
+}
+
+type CheckResult struct {
+	Status      Status     `json:"status"`
+	Critical    bool       `json:"critical"`
+	LatencyMS   float64    `json:"latency_ms"`
+	Error       string     `json:"error,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+type Report struct {
+	Status Status                 `json:"status"`
+	Time   string                 `json:"time"`
+	Uptime string                 `json:"uptime"`
+	Build  BuildInfo              `json:"build"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type lastError struct {
+	msg string
+	at  time.Time
+}
+
+// Registry хранит проверки и запоминает последнюю ошибку каждой из них.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []Check
+	lastErr map[string]lastError
+	started time.Time
+	build   BuildInfo
+}
+
+func NewRegistry(build BuildInfo) *Registry {
+	return &Registry{
+		lastErr: make(map[string]lastError),
+		started: time.Now(),
+		build:   build,
+	}
+}
+
+// Register добавляет проверку; имена должны быть уникальными.
+func (r *Registry) Register(c Check) error {
+	if c.Name == "" || c.Fn == nil {
+		return errors.New("health: check needs a name and a function")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.checks {
+		if existing.Name == c.Name {
+			return fmt.Errorf("health: check %q already registered", c.Name)
+		}
+	}
+	r.checks = append(r.checks, c)
+	return nil
+}
+
+// Run выполняет все проверки параллельно, каждую со своим таймаутом.
+// Итоговый статус — fail, если упала хоть одна критичная проверка.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]Check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now()
+	rep := Report{
+		Status: StatusOK,
+		Time:   now.UTC().Format(time.RFC3339),
+		Uptime: now.Sub(r.started).Round(time.Second).String(),
+		Build:  r.build,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for i, c := range checks {
+		res := results[i]
+		rep.Checks[c.Name] = res
+		if res.Status == StatusOK {
+			continue
+		}
+		if c.Critical {
+			rep.Status = StatusFail
+		} else if rep.Status == StatusOK {
+			rep.Status = StatusDegraded
+		}
+	}
+	return rep
+}
+
+func (r *Registry) runOne(ctx context.Context, c Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := call(ctx, c.Fn)
+	res := CheckResult{
+		Status:    StatusOK,
+		Critical:  c.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+		r.lastErr[c.Name] = lastError{msg: err.Error(), at: time.Now().UTC()}
+	}
+	if le, ok := r.lastErr[c.Name]; ok {
+		at := le.at
+		res.LastError, res.LastErrorAt = le.msg, &at
+	}
+	return res
+}
+
+// call выполняет проверку и не ждёт её дольше таймаута контекста;
+// паника внутри проверки считается её провалом.
+func call(ctx context.Context, fn func(context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("check panicked: %v", rec)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
+}
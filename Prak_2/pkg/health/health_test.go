@@ -0,0 +1,76 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"prak2/pkg/health"
+)
+
+func TestRegistry_CriticalFailureFailsReport(t *testing.T) {
+	reg := health.NewRegistry(health.ReadBuildInfo("1.0.0", "abc"))
+	mustRegister(t, reg, health.Check{Name: "db", Critical: true, Fn: func(context.Context) error { return errors.New("down") }})
+	mustRegister(t, reg, health.Check{Name: "cache", Fn: func(context.Context) error { return nil }})
+
+	rep := reg.Run(context.Background())
+	if rep.Status != health.StatusFail {
+		t.Fatalf("expected fail, got %s", rep.Status)
+	}
+	if got := rep.Checks["db"]; got.Status != health.StatusFail || got.Error != "down" || got.LastError != "down" {
+		t.Fatalf("unexpected db result %+v", got)
+	}
+	if rep.Checks["cache"].Status != health.StatusOK {
+		t.Fatalf("unexpected cache result %+v", rep.Checks["cache"])
+	}
+	if rep.Build.Version != "1.0.0" || rep.Build.Commit != "abc" || rep.Build.GoVersion == "" {
+		t.Fatalf("unexpected build info %+v", rep.Build)
+	}
+}
+
+func TestRegistry_TimeoutPanicAndLastError(t *testing.T) {
+	reg := health.NewRegistry(health.ReadBuildInfo("", ""))
+	fail := true
+	mustRegister(t, reg, health.Check{Name: "flaky", Fn: func(context.Context) error {
+		if fail {
+			return errors.New("flap")
+		}
+		return nil
+	}})
+	mustRegister(t, reg, health.Check{Name: "slow", Timeout: 20 * time.Millisecond, Fn: func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second) // проверка игнорирует отмену — отчёт не должен её ждать
+		return nil
+	}})
+	mustRegister(t, reg, health.Check{Name: "panics", Fn: func(context.Context) error { panic("boom") }})
+
+	start := time.Now()
+	rep := reg.Run(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("report waited for a stuck check")
+	}
+	if rep.Status != health.StatusDegraded {
+		t.Fatalf("expected degraded, got %s", rep.Status)
+	}
+	if rep.Checks["slow"].Status != health.StatusFail || rep.Checks["panics"].Status != health.StatusFail {
+		t.Fatalf("unexpected results %+v", rep.Checks)
+	}
+
+	fail = false
+	rep = reg.Run(context.Background())
+	flaky := rep.Checks["flaky"]
+	if flaky.Status != health.StatusOK || flaky.LastError != "flap" || flaky.LastErrorAt == nil {
+		t.Fatalf("last error should survive recovery: %+v", flaky)
+	}
+	if err := reg.Register(health.Check{Name: "flaky", Fn: func(context.Context) error { return nil }}); err == nil {
+		t.Fatalf("duplicate name must be rejected")
+	}
+}
+
+func mustRegister(t *testing.T, reg *health.Registry, c health.Check) {
+	t.Helper()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package httpkit — общие HTTP-помощники для сервисов: цепочки middleware,
+// id запроса, ошибки в формате problem+json (RFC 7807), строгий разбор JSON
+// и восстановление после паники.
+package httpkit
+
+import "net/http"
+
+// Middleware оборачивает обработчик.
+type Middleware func(http.Handler) http.Handler
+
+// Chain — упорядоченный набор middleware; первый элемент оказывается самым внешним.
+type Chain []Middleware
+
+func NewChain(mws ...Middleware) Chain {
+	return append(Chain(nil), mws...)
+}
+
+// Append возвращает новую цепочку, не изменяя исходную.
+func (c Chain) Append(mws ...Middleware) Chain {
+	out := make(Chain, 0, len(c)+len(mws))
+	out = append(out, c...)
+	return append(out, mws...)
+}
+
+// Then оборачивает h всеми middleware цепочки. nil означает http.DefaultServeMux.
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
+
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	return c.Then(fn)
+}
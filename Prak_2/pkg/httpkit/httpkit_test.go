@@ -0,0 +1,99 @@
+package httpkit_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prak2/pkg/httpkit"
+)
+
+func TestChain_OrderOutermostFirst(t *testing.T) {
+	var order []string
+	mw := func(name string) httpkit.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	h := httpkit.NewChain(mw("a"), mw("b")).Append(mw("c")).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := strings.Join(order, ","); got != "a,b,c,handler" {
+		t.Fatalf("unexpected order %s", got)
+	}
+}
+
+func TestRecover_WritesProblemWithRequestID(t *testing.T) {
+	h := httpkit.NewChain(httpkit.RequestID, httpkit.Recover).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/explode", nil)
+	req.Header.Set(httpkit.HeaderRequestID, "req-42")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != httpkit.ContentTypeProblem {
+		t.Fatalf("expected problem content type, got %q", ct)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body["status"] != float64(500) || body["instance"] != "/explode" || body["request_id"] != "req-42" {
+		t.Errorf("unexpected problem body: %v", body)
+	}
+}
+
+func TestDecodeJSON_Strict(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	cases := []struct {
+		name   string
+		ct     string
+		body   string
+		status int
+	}{
+		{"ok", "application/json", `{"name":"gopher"}`, 0},
+		{"unknown field", "application/json", `{"name":"gopher","age":3}`, http.StatusBadRequest},
+		{"trailing data", "application/json", `{"name":"a"}{"name":"b"}`, http.StatusBadRequest},
+		{"wrong type", "application/json", `{"name":1}`, http.StatusBadRequest},
+		{"empty", "application/json", ``, http.StatusBadRequest},
+		{"media type", "text/plain", `{"name":"gopher"}`, http.StatusUnsupportedMediaType},
+		{"too large", "application/json", `{"name":"` + strings.Repeat("x", httpkit.DefaultMaxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.ct)
+
+			var dst payload
+			err := httpkit.DecodeJSON(httptest.NewRecorder(), req, &dst)
+			if tc.status == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			p, ok := err.(*httpkit.Problem)
+			if !ok {
+				t.Fatalf("expected *Problem, got %T (%v)", err, err)
+			}
+			if p.Status != tc.status {
+				t.Errorf("expected status %d, got %d (%s)", tc.status, p.Status, p.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package httpkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes — ограничение размера тела для DecodeJSON.
+const DefaultMaxBodyBytes = 1 << 20
+
+// WriteJSON отправляет v в формате JSON с кодом code.
+func WriteJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// DecodeJSON строго разбирает тело запроса в dst: проверяет Content-Type,
+// ограничивает размер DefaultMaxBodyBytes, запрещает неизвестные поля и
+// лишние данные после объекта. Ошибка всегда *Problem с подходящим кодом.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return DecodeJSONLimit(w, r, dst, DefaultMaxBodyBytes)
+}
+
+// DecodeJSONLimit — то же, что DecodeJSON, с собственным лимитом размера тела.
+func DecodeJSONLimit(w http.ResponseWriter, r *http.Request, dst any, limit int64) error {
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mt != "application/json" && !strings.HasSuffix(mt, "+json")) {
+			return NewProblem(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		}
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeProblem(err, limit)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return NewProblem(http.StatusBadRequest, "body must contain a single JSON value")
+	}
+	return nil
+}
+
+func decodeProblem(err error, limit int64) *Problem {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		maxBytesErr  *http.MaxBytesError
+		detail       string
+		status       = http.StatusBadRequest
+		unknownField = "json: unknown field "
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		detail = fmt.Sprintf("malformed JSON at position %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		detail = "malformed JSON"
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			detail = fmt.Sprintf("field %q must be %s", typeErr.Field, typeErr.Type)
+		} else {
+			detail = fmt.Sprintf("value at position %d has the wrong type", typeErr.Offset)
+		}
+	case strings.HasPrefix(err.Error(), unknownField):
+		detail = "unknown field " + strings.TrimPrefix(err.Error(), unknownField)
+	case errors.Is(err, io.EOF):
+		detail = "body must not be empty"
+	case errors.As(err, &maxBytesErr):
+		status = http.StatusRequestEntityTooLarge
+		detail = fmt.Sprintf("body must not be larger than %d bytes", limit)
+	default:
+		detail = err.Error()
+	}
+	return NewProblem(status, detail)
+}
@@ -0,0 +1,105 @@
+package httpkit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// Problem — тело ошибки по RFC 7807. Реализует error, поэтому его можно
+// вернуть из вспомогательных функций и отдать клиенту через WriteError.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Extensions — дополнительные поля верхнего уровня, например "errors" или "request_id".
+	Extensions map[string]any `json:"-"`
+}
+
+// NewProblem создаёт Problem со стандартным заголовком для кода status.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With добавляет поле-расширение и возвращает тот же Problem.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	base, err := json.Marshal((*plain)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return base, err
+	}
+
+	fields := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		fields[k] = v
+	}
+	// стандартные поля важнее расширений с теми же именами
+	var std map[string]any
+	if err := json.Unmarshal(base, &std); err != nil {
+		return nil, err
+	}
+	for k, v := range std {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// WriteProblem отправляет p как application/problem+json. Если instance не задан,
+// подставляется путь запроса, а id запроса добавляется в поле request_id.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+	if r != nil {
+		if id := RequestIDFromContext(r.Context()); id != "" {
+			if _, set := p.Extensions["request_id"]; !set {
+				p.With("request_id", id)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Error — короткая запись для WriteProblem(w, r, NewProblem(status, detail)).
+func Error(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	WriteProblem(w, r, NewProblem(status, detail))
+}
+
+// WriteError отдаёт *Problem из цепочки err как есть, а любую другую ошибку —
+// как 500 без подробностей, чтобы не раскрывать внутренности клиенту.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var p *Problem
+	if errors.As(err, &p) {
+		WriteProblem(w, r, p)
+		return
+	}
+	WriteProblem(w, r, NewProblem(http.StatusInternalServerError, ""))
+}
@@ -0,0 +1,32 @@
+package httpkit
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover перехватывает панику в обработчике, пишет её со стеком в slog.Default()
+// и отвечает клиенту 500 в формате problem+json.
+// http.ErrAbortHandler пробрасывается дальше: это штатный способ оборвать ответ.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			slog.Default().ErrorContext(r.Context(), "panic recovered",
+				slog.Any("panic", rec),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("stack", string(debug.Stack())),
+			)
+			WriteProblem(w, r, NewProblem(http.StatusInternalServerError, ""))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,47 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prak2/pkg/httpkit"
+	"prak2/pkg/logging"
+)
+
+func TestAccessLog_AttachesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(logging.Options{Level: slog.LevelInfo, Format: logging.FormatJSON, Output: &buf})
+
+	h := httpkit.NewChain(httpkit.RequestID, httpkit.AccessLog(logger)).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpkit.WriteJSON(w, http.StatusTeapot, map[string]string{"status": "ok"})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(httpkit.HeaderRequestID, "demo-123")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v (%s)", err, buf.String())
+	}
+	if entry["request_id"] != "demo-123" || entry["status"] != float64(http.StatusTeapot) || entry["level"] != "WARN" {
+		t.Errorf("unexpected log entry: %v", entry)
+	}
+}
+
+func TestNew_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(logging.Options{Level: slog.LevelWarn, Output: &buf})
+
+	logger.Info("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("info record written at warn level: %s", buf.String())
+	}
+	logger.Warn("shown")
+	if !bytes.Contains(buf.Bytes(), []byte("shown")) {
+		t.Fatalf("warn record missing: %s", buf.String())
+	}
+}
@@ -1,20 +1,21 @@
 package utils
 
 import (
-	"encoding/json"
 	"net/http"
+
+	"prak2/pkg/httpkit"
 )
 
 type JSONError struct {
 	Error string `json:"error"`
 }
 
+// Deprecated: используйте httpkit.WriteJSON.
 func WriteJSON(w http.ResponseWriter, code int, v any) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(v)
+	httpkit.WriteJSON(w, code, v)
 }
 
+// Deprecated: используйте httpkit.Error — он отвечает в формате problem+json.
 func WriteErr(w http.ResponseWriter, code int, msg string) {
 	WriteJSON(w, code, JSONError{Error: msg})
 }
@@ -1,32 +1,32 @@
 package utils
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-	"fmt"
+	"log/slog"
 	"net/http"
-	"time"
+
+	"prak2/pkg/httpkit"
 )
 
+// Deprecated: используйте httpkit.NewRequestID.
 func NewID16() string {
-	b := make([]byte, 8)
-	_, _ = rand.Read(b)
-	return hex.EncodeToString(b)
+	return httpkit.NewRequestID()
 }
 
+// Deprecated: запросы логирует middleware httpkit.AccessLog.
 func LogRequest(r *http.Request) {
-	fmt.Printf("[%s] %s %s %s\n",
-		time.Now().Format(time.RFC3339),
-		r.RemoteAddr,
-		r.Method,
-		r.URL.Path,
+	slog.Default().InfoContext(r.Context(), "http request",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"remote_addr", r.RemoteAddr,
 	)
 }
 
+// Deprecated: используйте logging.Info или slog.
 func LogInfo(msg string) {
-	fmt.Printf("[INFO] %s %s\n", time.Now().Format(time.RFC3339), msg)
+	slog.Info(msg)
 }
 
+// Deprecated: используйте logging.Error или slog.
 func LogError(msg string) {
-	fmt.Printf("[ERROR] %s %s\n", time.Now().Format(time.RFC3339), msg)
+	slog.Error(msg)
 }
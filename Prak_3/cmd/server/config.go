@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"prak3/internal/api"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// serverConfig — параметры HTTP-сервера из переменных окружения.
+type serverConfig struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// DrainDelay — пауза между снятием готовности и остановкой сервера,
+	// чтобы балансировщик успел убрать инстанс из ротации.
+	DrainDelay      time.Duration
+	ShutdownTimeout time.Duration
+}
+
+func loadServerConfig() (serverConfig, error) {
+	cfg := serverConfig{Addr: getAddr()}
+
+	durations := []struct {
+		env string
+		dst *time.Duration
+		def time.Duration
+	}{
+		{"READ_TIMEOUT", &cfg.ReadTimeout, 10 * time.Second},
+		{"READ_HEADER_TIMEOUT", &cfg.ReadHeaderTimeout, 5 * time.Second},
+		{"WRITE_TIMEOUT", &cfg.WriteTimeout, 15 * time.Second},
+		{"IDLE_TIMEOUT", &cfg.IdleTimeout, 60 * time.Second},
+		{"DRAIN_DELAY", &cfg.DrainDelay, 0},
+		{"SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout, 15 * time.Second},
+	}
+	for _, d := range durations {
+		v, err := envDuration(d.env, d.def)
+		if err != nil {
+			return cfg, err
+		}
+		*d.dst = v
+	}
+	return cfg, nil
+}
+
+func getAddr() string {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return ":" + port
+}
+
+// envDuration читает длительность в формате time.ParseDuration, например "30s".
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: invalid duration %q", key, raw)
+	}
+	return d, nil
+}
+
+// newLogger собирает slog-логгер по LOG_LEVEL (debug, info, warn, error)
+// и LOG_FORMAT (json по умолчанию или text).
+func newLogger(w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if raw := os.Getenv("LOG_LEVEL"); raw != "" {
+		if err := level.UnmarshalText([]byte(raw)); err != nil {
+			return nil, fmt.Errorf("LOG_LEVEL: %w", err)
+		}
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch format := strings.ToLower(os.Getenv("LOG_FORMAT")); format {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT: unknown format %q", format)
+	}
+}
+
+// loadCORSPolicy дополняет политику по умолчанию значениями из CORS_ALLOWED_ORIGINS
+// (через запятую), CORS_ALLOW_CREDENTIALS и CORS_MAX_AGE.
+func loadCORSPolicy() (api.CORSPolicy, error) {
+	p := api.DefaultCORSPolicy()
+
+	if raw := os.Getenv("CORS_ALLOWED_ORIGINS"); raw != "" {
+		p.AllowedOrigins = nil
+		for _, o := range strings.Split(raw, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				p.AllowedOrigins = append(p.AllowedOrigins, o)
+			}
+		}
+	}
+	if raw := os.Getenv("CORS_ALLOW_CREDENTIALS"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return p, fmt.Errorf("CORS_ALLOW_CREDENTIALS: invalid bool %q", raw)
+		}
+		p.AllowCredentials = v
+	}
+	maxAge, err := envDuration("CORS_MAX_AGE", p.MaxAge)
+	if err != nil {
+		return p, err
+	}
+	p.MaxAge = maxAge
+
+	return p, p.Validate()
+}
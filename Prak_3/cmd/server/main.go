@@ -2,71 +2,125 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"prak3/internal/api"
 	"prak3/internal/storage"
+	"syscall"
 	"time"
 )
 
 func main() {
-	store := storage.NewMemoryStore()
+	logger, err := newLogger(os.Stderr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
+
+	if err := run(logger); err != nil {
+		logger.Error("server failed", "err", err)
+		os.Exit(1)
+	}
+	logger.Info("server exited gracefully")
+}
+
+func run(logger *slog.Logger) error {
+	cfg, err := loadServerConfig()
+	if err != nil {
+		return err
+	}
+	corsPolicy, err := loadCORSPolicy()
+	if err != nil {
+		return err
+	}
+
+	store, err := newStore()
+	if err != nil {
+		return err
+	}
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
 	h := api.NewHandlers(store)
+	probes := api.NewProbes()
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
-		api.JSON(w, http.StatusOK, map[string]string{"status": "ok"})
-	})
+	mux.HandleFunc("GET /health", probes.Livez)
+	mux.HandleFunc("GET /livez", probes.Livez)
+	mux.HandleFunc("GET /readyz", probes.Readyz)
 
 	mux.HandleFunc("GET /tasks", h.ListTasks)
 	mux.HandleFunc("POST /tasks", h.CreateTask)
+	mux.HandleFunc("POST /tasks:batch", h.BatchTasks)
 	mux.HandleFunc("PATCH /tasks/", h.UpdateTask)
 	mux.HandleFunc("DELETE /tasks/", h.DeleteTask)
 	mux.HandleFunc("GET /tasks/", h.GetTask)
 
-	handler := api.WithCORS(api.Logging(mux))
-	addr := getAddr()
+	handler := api.WithCORS(corsPolicy)(api.RequestID(api.Logging(logger)(mux)))
 	server := &http.Server{
-		Addr:    addr,
-		Handler: handler,
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
 	}
 
-	log.Println("listening on", addr)
-	if err := http.ListenAndServe(addr, handler); err != nil {
-		log.Fatal(err)
-	}
-
-	// канал для прослушивания сигналов прерывания или завершения
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	// контекст отменяется при SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	serveErr := make(chan error, 1)
 	go func() {
-		log.Println("listening on", addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("ListenAndServe: %v", err)
+		logger.Info("listening", "addr", cfg.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
 		}
+		close(serveErr)
 	}()
+	probes.SetReady(true)
 
-	<-stop // ожидание сигнала
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("ListenAndServe: %w", err)
+	case <-ctx.Done():
+	}
+	stop()
 
-	log.Println("Shutting down server...")
+	logger.Info("shutting down server")
+	probes.SetReady(false)
+	if cfg.DrainDelay > 0 {
+		logger.Info("draining before closing listeners", "delay", cfg.DrainDelay.String())
+		time.Sleep(cfg.DrainDelay)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server Shutdown Failed:%+v", err)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown failed: %w", err)
 	}
-
-	log.Println("Server exited gracefully")
-
+	return nil
 }
 
-func getAddr() string {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// newStore выбирает хранилище по STORAGE_BACKEND: memory (по умолчанию) или wal.
+func newStore() (storage.TaskStore, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "memory":
+		return storage.NewMemoryStore(), nil
+	case "wal":
+		path := os.Getenv("STORAGE_PATH")
+		if path == "" {
+			path = "data/tasks.wal"
+		}
+		slog.Info("using wal storage", "path", path)
+		return storage.NewWALStore(path)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
 	}
-	return ":" + port
 }
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"prak3/internal/storage"
+)
+
+const maxBatchOps = 1000
+
+type batchRequest struct {
+	Operations []batchOpRequest `json:"operations"`
+}
+
+// batchOpRequest — одна операция: "task" имеет тот же формат, что и тело POST или PATCH.
+// if_version — необязательная ожидаемая версия задачи, аналог If-Match.
+type batchOpRequest struct {
+	Op        string          `json:"op"`
+	ID        int64           `json:"id"`
+	IfVersion int64           `json:"if_version"`
+	Task      json.RawMessage `json:"task"`
+}
+
+type batchItemResponse struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	Status int           `json:"status"`
+	ID     int64         `json:"id,omitempty"`
+	Task   *storage.Task `json:"task,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Error   string              `json:"error,omitempty"`
+	Results []batchItemResponse `json:"results"`
+}
+
+// POST /tasks:batch — create/update/delete пачкой; при любой ошибке не применяется ничего.
+// Неудачные операции получают свой код ошибки, остальные — 424 Failed Dependency.
+func (h *Handlers) BatchTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "" && !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		BadRequest(w, "Content-Type must be application/json")
+		return
+	}
+
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		BadRequest(w, "invalid json: "+err.Error())
+		return
+	}
+	if len(req.Operations) == 0 {
+		BadRequest(w, "operations must not be empty")
+		return
+	}
+	if len(req.Operations) > maxBatchOps {
+		BadRequest(w, fmt.Sprintf("at most %d operations per batch", maxBatchOps))
+		return
+	}
+
+	ops := make([]storage.BatchOp, len(req.Operations))
+	items := make([]batchItemResponse, len(req.Operations))
+	malformed := false
+	for i, raw := range req.Operations {
+		items[i] = batchItemResponse{Index: i, Op: raw.Op, ID: raw.ID}
+		op, err := raw.toOp()
+		if err != nil {
+			items[i].Status, items[i].Error = http.StatusBadRequest, err.Error()
+			malformed = true
+			continue
+		}
+		ops[i] = op
+	}
+	if malformed {
+		failBatch(w, items)
+		return
+	}
+
+	results, err := h.Store.Batch(ops)
+	if err != nil && !errors.Is(err, storage.ErrBatchAborted) {
+		storeError(w, err)
+		return
+	}
+	for i, res := range results {
+		items[i].ID, items[i].Task = res.ID, res.Task
+		if res.Err != nil {
+			items[i].Status, items[i].Error = batchItemStatus(res.Err), res.Err.Error()
+			continue
+		}
+		switch res.Op {
+		case storage.BatchCreate:
+			items[i].Status = http.StatusCreated
+		case storage.BatchDelete:
+			items[i].Status = http.StatusNoContent
+		default:
+			items[i].Status = http.StatusOK
+		}
+	}
+	if err != nil {
+		failBatch(w, items)
+		return
+	}
+	JSON(w, http.StatusOK, batchResponse{Results: items})
+}
+
+func (op batchOpRequest) toOp() (storage.BatchOp, error) {
+	switch storage.BatchOpKind(op.Op) {
+	case storage.BatchCreate:
+		var req createTaskRequest
+		if err := json.Unmarshal(op.Task, &req); err != nil {
+			return storage.BatchOp{}, errors.New("invalid task: " + err.Error())
+		}
+		return storage.BatchOp{Op: storage.BatchCreate, Create: &storage.TaskCreatePayload{
+			Title:       req.Title,
+			Description: req.Description,
+			Priority:    storage.Priority(req.Priority),
+			DueDate:     req.DueDate,
+			Tags:        req.Tags,
+		}}, nil
+
+	case storage.BatchUpdate:
+		if op.ID <= 0 {
+			return storage.BatchOp{}, errors.New("id is required")
+		}
+		var req updateTaskRequest
+		if err := json.Unmarshal(op.Task, &req); err != nil {
+			return storage.BatchOp{}, errors.New("invalid task: " + err.Error())
+		}
+		p, err := req.payload()
+		if err != nil {
+			return storage.BatchOp{}, err
+		}
+		p.IfVersion = op.IfVersion
+		return storage.BatchOp{Op: storage.BatchUpdate, ID: op.ID, Update: &p}, nil
+
+	case storage.BatchDelete:
+		if op.ID <= 0 {
+			return storage.BatchOp{}, errors.New("id is required")
+		}
+		return storage.BatchOp{Op: storage.BatchDelete, ID: op.ID, IfVersion: op.IfVersion}, nil
+	}
+	return storage.BatchOp{}, errors.New("op must be one of create, update, delete")
+}
+
+// failBatch отвечает 422: операции без собственной ошибки помечаются как откаченные.
+func failBatch(w http.ResponseWriter, items []batchItemResponse) {
+	for i := range items {
+		if items[i].Error == "" {
+			items[i].Status = http.StatusFailedDependency
+			items[i].Task = nil
+		}
+		if items[i].Op == string(storage.BatchCreate) {
+			items[i].ID = 0
+		}
+	}
+	JSON(w, http.StatusUnprocessableEntity, batchResponse{Error: storage.ErrBatchAborted.Error(), Results: items})
+}
+
+func batchItemStatus(err error) int {
+	var verr *storage.ValidationError
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.As(err, &verr):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy — правила CORS. Origin задаётся точно ("https://app.example.com"),
+// с маской поддомена ("https://*.example.com") или "*" для любого источника.
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCORSPolicy разрешает любые источники без cookies — поведение по умолчанию для локальной разработки.
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete},
+		AllowedHeaders: []string{"Content-Type", requestIDHeader, "If-Match", "If-None-Match"},
+		ExposedHeaders: []string{"X-Total-Count", "Link", requestIDHeader, "ETag"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// Validate отбрасывает конфигурации, которые браузеры всё равно не примут.
+func (p CORSPolicy) Validate() error {
+	if len(p.AllowedOrigins) == 0 {
+		return errors.New("cors: at least one allowed origin is required")
+	}
+	for _, o := range p.AllowedOrigins {
+		if o == "*" && p.AllowCredentials {
+			return errors.New(`cors: wildcard origin "*" cannot be combined with credentials`)
+		}
+		if strings.Count(o, "*") > 1 || (o != "*" && strings.Contains(o, "*") && !strings.Contains(o, "://*.")) {
+			return errors.New("cors: invalid origin pattern " + o)
+		}
+	}
+	return nil
+}
+
+// WithCORS применяет политику: отвечает на preflight-запросы от разрешённых
+// источников и добавляет CORS-заголовки к обычным ответам.
+// OPTIONS без Access-Control-Request-Method передаётся дальше как обычный запрос.
+func WithCORS(p CORSPolicy) func(http.Handler) http.Handler {
+	c := newCORS(p)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && origin != "" &&
+				r.Header.Get("Access-Control-Request-Method") != ""
+
+			h := w.Header()
+			if c.varyOrigin {
+				h.Add("Vary", "Origin")
+			}
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				c.preflight(w, r, origin)
+				return
+			}
+
+			if origin != "" && c.originAllowed(origin) {
+				c.setOrigin(h, origin)
+				if c.exposed != "" {
+					h.Set("Access-Control-Expose-Headers", c.exposed)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type cors struct {
+	anyOrigin   bool
+	exact       map[string]struct{}
+	wildcards   [][2]string // пары префикс/суффикс для масок поддоменов
+	methods     map[string]struct{}
+	headers     map[string]struct{}
+	allowMethod string
+	allowHeader string
+	exposed     string
+	credentials bool
+	maxAge      string
+	varyOrigin  bool
+}
+
+func newCORS(p CORSPolicy) *cors {
+	c := &cors{
+		exact:       make(map[string]struct{}),
+		methods:     make(map[string]struct{}),
+		headers:     make(map[string]struct{}),
+		allowMethod: strings.Join(p.AllowedMethods, ", "),
+		allowHeader: strings.Join(p.AllowedHeaders, ", "),
+		exposed:     strings.Join(p.ExposedHeaders, ", "),
+		credentials: p.AllowCredentials,
+	}
+	for _, o := range p.AllowedOrigins {
+		o = strings.ToLower(strings.TrimSpace(o))
+		switch {
+		case o == "*":
+			c.anyOrigin = true
+		case strings.Contains(o, "*"):
+			i := strings.Index(o, "*")
+			c.wildcards = append(c.wildcards, [2]string{o[:i], o[i+1:]})
+		default:
+			c.exact[o] = struct{}{}
+		}
+	}
+	for _, m := range p.AllowedMethods {
+		c.methods[strings.ToUpper(m)] = struct{}{}
+	}
+	for _, hdr := range p.AllowedHeaders {
+		c.headers[http.CanonicalHeaderKey(hdr)] = struct{}{}
+	}
+	if p.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(p.MaxAge / time.Second))
+	}
+	// ответ зависит от Origin всегда, кроме случая "*" без cookies
+	c.varyOrigin = !c.anyOrigin || c.credentials
+	return c
+}
+
+func (c *cors) originAllowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if _, ok := c.exact[origin]; ok {
+		return true
+	}
+	for _, wc := range c.wildcards {
+		prefix, suffix := wc[0], wc[1]
+		if len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+		sub := origin[len(prefix) : len(origin)-len(suffix)]
+		if !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) setOrigin(h http.Header, origin string) {
+	if c.anyOrigin && !c.credentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// preflight отвечает 204 с разрешениями или 403 без CORS-заголовков,
+// если источник, метод или заголовки не разрешены политикой.
+func (c *cors) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	if !c.originAllowed(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if _, ok := c.methods[strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))]; !ok {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	for _, hdr := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		hdr = strings.TrimSpace(hdr)
+		if hdr == "" {
+			continue
+		}
+		if _, ok := c.headers[http.CanonicalHeaderKey(hdr)]; !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	h := w.Header()
+	c.setOrigin(h, origin)
+	h.Set("Access-Control-Allow-Methods", c.allowMethod)
+	if c.allowHeader != "" {
+		h.Set("Access-Control-Allow-Headers", c.allowHeader)
+	}
+	if c.maxAge != "" {
+		h.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
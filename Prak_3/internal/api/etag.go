@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"prak3/internal/storage"
+)
+
+// etag — сильный ETag задачи, построенный из её версии.
+func etag(t *storage.Task) string {
+	return `"` + strconv.FormatInt(t.Version, 10) + `"`
+}
+
+// parseETags разбирает список ETag из If-Match/If-None-Match.
+// Для If-Match (weak = false) слабые теги W/"..." не подходят ни под одну версию.
+// Теги, которые сервер не выдавал, просто пропускаются.
+func parseETags(header string, weak bool) (wildcard bool, versions []int64) {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			wildcard = true
+			continue
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil && v > 0 {
+			versions = append(versions, v)
+		}
+	}
+	return wildcard, versions
+}
+
+// ifMatchVersion переводит If-Match в версию для условного изменения в хранилище
+// (0 — без условия). Если условие заведомо не выполняется, отвечает 412 и возвращает false.
+func (h *Handlers) ifMatchVersion(w http.ResponseWriter, r *http.Request, id int64) (int64, bool) {
+	raw := r.Header.Get("If-Match")
+	if raw == "" {
+		return 0, true
+	}
+	wildcard, versions := parseETags(raw, false)
+	if len(versions) == 1 && !wildcard {
+		return versions[0], true
+	}
+
+	t, err := h.Store.Get(id)
+	if err != nil {
+		PreconditionFailed(w, "task does not match If-Match")
+		return 0, false
+	}
+	if wildcard {
+		return 0, true
+	}
+	for _, v := range versions {
+		if v == t.Version {
+			// хранилище повторно сверит версию под блокировкой
+			return v, true
+		}
+	}
+	PreconditionFailed(w, "task does not match If-Match")
+	return 0, false
+}
+
+// conditionalStoreError — storeError для изменений с If-Match: условие относится
+// к существующей задаче, поэтому её отсутствие (в том числе удаление между
+// проверкой и записью) — 412, а не 404 (RFC 9110, 13.1.1).
+func conditionalStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, storage.ErrNotFound) && r.Header.Get("If-Match") != "" {
+		PreconditionFailed(w, "task does not match If-Match")
+		return
+	}
+	storeError(w, err)
+}
+
+// notModified отвечает 304, если If-None-Match совпадает с текущей версией задачи.
+func notModified(w http.ResponseWriter, r *http.Request, t *storage.Task) bool {
+	raw := r.Header.Get("If-None-Match")
+	if raw == "" {
+		return false
+	}
+	wildcard, versions := parseETags(raw, true)
+	match := wildcard
+	for _, v := range versions {
+		if v == t.Version {
+			match = true
+		}
+	}
+	if !match {
+		return false
+	}
+	w.Header().Set("ETag", etag(t))
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
@@ -6,39 +6,50 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"prak3/internal/storage"
 )
 
 type Handlers struct {
-	Store *storage.MemoryStore
+	Store storage.TaskStore
 }
 
-func NewHandlers(store *storage.MemoryStore) *Handlers {
+func NewHandlers(store storage.TaskStore) *Handlers {
 	return &Handlers{Store: store}
 }
 
-// GET /tasks
+// GET /tasks?q=&done=&tag=&sort=&order=&limit=&offset=&cursor=
 func (h *Handlers) ListTasks(w http.ResponseWriter, r *http.Request) {
-	tasks := h.Store.List()
-
-	// Поддержка простых фильтров через query: ?q=text
-	q := strings.TrimSpace(r.URL.Query().Get("q"))
-	if q != "" {
-		filtered := tasks[:0]
-		for _, t := range tasks {
-			if strings.Contains(strings.ToLower(t.Title), strings.ToLower(q)) {
-				filtered = append(filtered, t)
-			}
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
+
+	page, err := h.Store.List(q)
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			BadRequest(w, err.Error())
+			return
 		}
-		tasks = filtered
+		storeError(w, err)
+		return
 	}
 
-	JSON(w, http.StatusOK, tasks)
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if link := pageLinks(r.URL, q, page); link != "" {
+		w.Header().Set("Link", link)
+	}
+	JSON(w, http.StatusOK, page.Items)
 }
 
 type createTaskRequest struct {
-	Title string `json:"title"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+	Tags        []string   `json:"tags"`
 }
 
 // POST /tasks
@@ -53,52 +64,82 @@ func (h *Handlers) CreateTask(w http.ResponseWriter, r *http.Request) {
 		BadRequest(w, "invalid json: "+err.Error())
 		return
 	}
-	req.Title = strings.TrimSpace(req.Title)
-	if req.Title == "" {
+	if strings.TrimSpace(req.Title) == "" {
 		BadRequest(w, "title is required")
 		return
 	}
 
-	runes := []rune(req.Title)
-	if len(runes) < 3 || len(runes) > 140 {
-		UnprocessableEntity(w, "title size should be between 3 and 140")
+	t, err := h.Store.Create(storage.TaskCreatePayload{
+		Title:       req.Title,
+		Description: req.Description,
+		Priority:    storage.Priority(req.Priority),
+		DueDate:     req.DueDate,
+		Tags:        req.Tags,
+	})
+	if err != nil {
+		storeError(w, err)
 		return
 	}
-
-	t := h.Store.Create(req.Title)
+	w.Header().Set("ETag", etag(t))
 	JSON(w, http.StatusCreated, t)
 }
 
 // GET /tasks/{id} (простой path-парсер без стороннего роутера)
 func (h *Handlers) GetTask(w http.ResponseWriter, r *http.Request) {
-	// Ожидаем путь вида /tasks/123
-	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	if len(parts) != 2 {
-		NotFound(w, "invalid path")
-		return
-	}
-	id, err := strconv.ParseInt(parts[1], 10, 64)
+	id, err := extractIDFromPath(w, r)
 	if err != nil {
-		BadRequest(w, "invalid id")
 		return
 	}
 
 	t, err := h.Store.Get(id)
 	if err != nil {
-		if err.Error() == "not found" {
-			NotFound(w, "task not found")
-			return
-		}
-		Internal(w, "unexpected error")
+		storeError(w, err)
+		return
+	}
+	if notModified(w, r, t) {
 		return
 	}
+	w.Header().Set("ETag", etag(t))
 	JSON(w, http.StatusOK, t)
 }
 
+// updateTaskRequest — тело PATCH: отсутствующие поля не меняются,
+// а "due_date": null снимает срок выполнения.
 type updateTaskRequest struct {
-	Done bool `json:"done"`
+	Title       *string         `json:"title"`
+	Description *string         `json:"description"`
+	Priority    *string         `json:"priority"`
+	DueDate     json.RawMessage `json:"due_date"`
+	Tags        *[]string       `json:"tags"`
+	Done        *bool           `json:"done"`
 }
 
+func (req updateTaskRequest) payload() (storage.TaskUpdatePayload, error) {
+	p := storage.TaskUpdatePayload{
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Done:        req.Done,
+	}
+	if req.Priority != nil {
+		pr := storage.Priority(*req.Priority)
+		p.Priority = &pr
+	}
+	switch {
+	case req.DueDate == nil:
+	case string(req.DueDate) == "null":
+		p.ClearDueDate = true
+	default:
+		var due time.Time
+		if err := json.Unmarshal(req.DueDate, &due); err != nil {
+			return p, errors.New("due_date must be an RFC 3339 timestamp or null")
+		}
+		p.DueDate = &due
+	}
+	return p, nil
+}
+
+// PATCH /tasks/{id}
 func (h *Handlers) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-Type") != "" && !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
 		BadRequest(w, "Content-Type must be application/json")
@@ -110,28 +151,47 @@ func (h *Handlers) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		BadRequest(w, "invalid json: "+err.Error())
 		return
 	}
+	payload, err := req.payload()
+	if err != nil {
+		BadRequest(w, err.Error())
+		return
+	}
 
 	id, err := extractIDFromPath(w, r)
 	if err != nil {
 		return
 	}
+	ifVersion, ok := h.ifMatchVersion(w, r, id)
+	if !ok {
+		return
+	}
+	payload.IfVersion = ifVersion
 
-	t := h.Store.Update(id, storage.TaskUpdatePayload{Done: req.Done})
-	if t == nil {
-		NotFound(w, "task not found")
+	t, err := h.Store.Update(id, payload)
+	if err != nil {
+		conditionalStoreError(w, r, err)
 		return
 	}
 
+	w.Header().Set("ETag", etag(t))
 	JSON(w, http.StatusOK, t)
 }
 
+// DELETE /tasks/{id}
 func (h *Handlers) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	id, err := extractIDFromPath(w, r)
 	if err != nil {
 		return
 	}
 
-	h.Store.Delete(id)
+	ifVersion, ok := h.ifMatchVersion(w, r, id)
+	if !ok {
+		return
+	}
+	if err := h.Store.Delete(id, ifVersion); err != nil {
+		conditionalStoreError(w, r, err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -148,3 +208,18 @@ func extractIDFromPath(w http.ResponseWriter, r *http.Request) (int64, error) {
 	}
 	return id, nil
 }
+
+// storeError переводит ошибку хранилища в HTTP-ответ.
+func storeError(w http.ResponseWriter, err error) {
+	var verr *storage.ValidationError
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		NotFound(w, "task not found")
+	case errors.Is(err, storage.ErrVersionMismatch):
+		PreconditionFailed(w, "task was modified by someone else")
+	case errors.As(err, &verr):
+		UnprocessableEntity(w, verr.Error())
+	default:
+		Internal(w, "storage error")
+	}
+}
@@ -1,41 +1,145 @@
 package api
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 )
 
-type statusRecorder struct {
+const requestIDHeader = "X-Request-Id"
+
+type ctxKey int
+
+const requestIDKey ctxKey = iota
+
+// accessRecorder пропускает ответ к клиенту и попутно запоминает для access-лога
+// код ответа и размер тела. Нулевой code — обработчик ещё ничего не отправил.
+type accessRecorder struct {
 	http.ResponseWriter
-	status int
+	code int
+	size int
+}
+
+func (a *accessRecorder) WriteHeader(code int) {
+	if a.code == 0 {
+		a.code = code
+	}
+	a.ResponseWriter.WriteHeader(code)
+}
+
+func (a *accessRecorder) Write(b []byte) (int, error) {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	n, err := a.ResponseWriter.Write(b)
+	a.size += n
+	return n, err
+}
+
+// Flush оставляет обёрнутый writer http.Flusher'ом; остальное (Hijack, дедлайны)
+// http.ResponseController найдёт через Unwrap.
+func (a *accessRecorder) Flush() {
+	if a.code == 0 {
+		a.code = http.StatusOK
+	}
+	_ = http.NewResponseController(a.ResponseWriter).Flush()
 }
 
-func (sr *statusRecorder) WriteHeader(code int) {
-	sr.status = code
-	sr.ResponseWriter.WriteHeader(code)
+func (a *accessRecorder) Unwrap() http.ResponseWriter {
+	return a.ResponseWriter
 }
 
-func Logging(next http.Handler) http.Handler {
+// status — код для лога: если обработчик ничего не записал, сервер ответит 200.
+func (a *accessRecorder) status() int {
+	if a.code == 0 {
+		return http.StatusOK
+	}
+	return a.code
+}
+
+// RequestID берёт X-Request-Id из запроса или генерирует новый,
+// кладёт его в контекст и возвращает клиенту в ответе.
+func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		rec := &statusRecorder{ResponseWriter: w, status: 200}
-		next.ServeHTTP(rec, r)
-		log.Printf("%s %s %d %v", r.Method, r.URL.Path, rec.status, time.Since(start))
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
 	})
 }
 
-func WithCORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// RequestIDFromContext возвращает id запроса, выставленный middleware RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
+// Logging пишет структурированную строку access-лога на каждый запрос.
+// Должен стоять ближе к mux, чем RequestID: маршрут (r.Pattern) mux выставляет
+// в тот же *http.Request, который получил от Logging.
+func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &accessRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
 
-		next.ServeHTTP(w, r)
-	})
+			status := rec.status()
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+
+			route := r.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+			logger.LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("route", route),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", rec.size),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_ip", remoteIP(r)),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+			)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// maxRequestIDLen ограничивает чужой X-Request-Id: он попадает в каждую строку лога.
+const maxRequestIDLen = 128
+
+// validRequestID принимает id клиента, только если это непустая строка видимых
+// ASCII-символов без пробелов, иначе RequestID сгенерирует свой.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(c rune) bool { return c <= ' ' || c > '~' })
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
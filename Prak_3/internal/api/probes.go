@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Probes — состояние сервера для оркестратора: /livez отвечает, пока процесс жив,
+// /readyz — только пока сервер готов принимать новый трафик.
+type Probes struct {
+	ready atomic.Bool
+}
+
+func NewProbes() *Probes {
+	return &Probes{}
+}
+
+func (p *Probes) SetReady(ready bool) {
+	p.ready.Store(ready)
+}
+
+func (p *Probes) Ready() bool {
+	return p.ready.Load()
+}
+
+// GET /livez
+func (p *Probes) Livez(w http.ResponseWriter, r *http.Request) {
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GET /readyz
+func (p *Probes) Readyz(w http.ResponseWriter, r *http.Request) {
+	if !p.Ready() {
+		JSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		return
+	}
+	JSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
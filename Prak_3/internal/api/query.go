@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"prak3/internal/storage"
+)
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 500
+)
+
+// parseListQuery разбирает параметры GET /tasks.
+func parseListQuery(v url.Values) (storage.ListQuery, error) {
+	q := storage.ListQuery{
+		Search: strings.TrimSpace(v.Get("q")),
+		Sort:   storage.SortByID,
+		Limit:  defaultPageLimit,
+		Cursor: v.Get("cursor"),
+	}
+
+	if raw := v.Get("done"); raw != "" {
+		done, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, errors.New("done must be true or false")
+		}
+		q.Done = &done
+	}
+
+	for _, tag := range v["tag"] {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			q.Tags = append(q.Tags, tag)
+		}
+	}
+
+	if raw := v.Get("sort"); raw != "" {
+		// "-due" — короткая запись для sort=due&order=desc
+		if strings.HasPrefix(raw, "-") {
+			raw = raw[1:]
+			q.Desc = true
+		}
+		q.Sort = storage.SortField(raw)
+		if !q.Sort.Valid() {
+			return q, errors.New("sort must be one of id, title, created, due, priority")
+		}
+	}
+	switch v.Get("order") {
+	case "":
+	case "asc":
+		q.Desc = false
+	case "desc":
+		q.Desc = true
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+
+	if raw := v.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+		}
+		q.Limit = limit
+	}
+	if raw := v.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return q, errors.New("offset must be a non-negative integer")
+		}
+		if q.Cursor != "" && offset > 0 {
+			return q, errors.New("offset and cursor cannot be combined")
+		}
+		q.Offset = offset
+	}
+	return q, nil
+}
+
+// pageLinks собирает заголовок Link (RFC 8288) со ссылками next и prev.
+func pageLinks(u *url.URL, q storage.ListQuery, page storage.ListPage) string {
+	var links []string
+	if page.NextCursor != "" {
+		v := u.Query()
+		v.Del("offset")
+		v.Set("cursor", page.NextCursor)
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="next"`, u.Path, v.Encode()))
+	}
+	if q.Cursor == "" && q.Offset > 0 {
+		v := u.Query()
+		prev := q.Offset - q.Limit
+		if prev > 0 {
+			v.Set("offset", strconv.Itoa(prev))
+		} else {
+			v.Del("offset")
+		}
+		links = append(links, fmt.Sprintf(`<%s?%s>; rel="prev"`, u.Path, v.Encode()))
+	}
+	return strings.Join(links, ", ")
+}
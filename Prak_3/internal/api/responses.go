@@ -27,6 +27,10 @@ func NotFound(w http.ResponseWriter, msg string) {
 	JSON(w, http.StatusNotFound, ErrorResponse{Error: msg})
 }
 
+func PreconditionFailed(w http.ResponseWriter, msg string) {
+	JSON(w, http.StatusPreconditionFailed, ErrorResponse{Error: msg})
+}
+
 func Internal(w http.ResponseWriter, msg string) {
 	JSON(w, http.StatusInternalServerError, ErrorResponse{Error: msg})
 }
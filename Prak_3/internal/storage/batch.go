@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBatchAborted — хотя бы одна операция пакета не прошла, изменения не применены.
+	ErrBatchAborted = errors.New("batch aborted, no changes applied")
+	// ErrInvalidBatchOp — операция пакета описана некорректно.
+	ErrInvalidBatchOp = errors.New("invalid batch operation")
+)
+
+type BatchOpKind string
+
+const (
+	BatchCreate BatchOpKind = "create"
+	BatchUpdate BatchOpKind = "update"
+	BatchDelete BatchOpKind = "delete"
+)
+
+// BatchOp — одна операция пакета. Для create заполняется Create,
+// для update — ID и Update, для delete — ID и, при желании, IfVersion.
+type BatchOp struct {
+	Op        BatchOpKind
+	ID        int64
+	IfVersion int64
+	Create    *TaskCreatePayload
+	Update    *TaskUpdatePayload
+}
+
+// BatchResult — итог операции с тем же индексом, что и в запросе.
+type BatchResult struct {
+	Op   BatchOpKind
+	ID   int64
+	Task *Task // nil для delete и для неудачных операций
+	Err  error
+}
+
+// Batch применяет все операции под одной блокировкой: либо все, либо ни одной.
+// Операции проверяются по очереди с учётом предыдущих (удалённую в пакете задачу
+// нельзя обновить), при ошибках результаты содержат причину по каждой операции,
+// а сама функция возвращает ErrBatchAborted.
+func (s *MemoryStore) Batch(ops []BatchOp) ([]BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results, staged, nextID, err := s.stageLocked(ops)
+	if err != nil {
+		return results, err
+	}
+
+	for id, t := range staged {
+		s.setLocked(id, t)
+	}
+	s.auto = nextID
+	return results, nil
+}
+
+// stageLocked готовит изменения пакета, не трогая хранилище.
+// В staged nil означает удаление задачи.
+func (s *MemoryStore) stageLocked(ops []BatchOp) ([]BatchResult, map[int64]*Task, int64, error) {
+	results := make([]BatchResult, len(ops))
+	staged := make(map[int64]*Task)
+	nextID := s.auto
+	now := time.Now().UTC()
+	failed := false
+
+	lookup := func(id int64) (*Task, bool) {
+		if t, ok := staged[id]; ok {
+			return t, t != nil
+		}
+		t, ok := s.tasks[id]
+		return t, ok
+	}
+
+	for i, op := range ops {
+		res := &results[i]
+		res.Op, res.ID = op.Op, op.ID
+
+		switch op.Op {
+		case BatchCreate:
+			if op.Create == nil {
+				res.Err = ErrInvalidBatchOp
+				break
+			}
+			p := *op.Create
+			if err := p.Normalize(); err != nil {
+				res.Err = err
+				break
+			}
+			nextID++
+			t := newTask(nextID, p, now)
+			staged[t.ID] = t
+			res.ID, res.Task = t.ID, t
+
+		case BatchUpdate:
+			if op.Update == nil {
+				res.Err = ErrInvalidBatchOp
+				break
+			}
+			p := *op.Update
+			if err := p.Normalize(); err != nil {
+				res.Err = err
+				break
+			}
+			prev, ok := lookup(op.ID)
+			if !ok {
+				res.Err = ErrNotFound
+				break
+			}
+			if p.IfVersion != 0 && p.IfVersion != prev.Version {
+				res.Err = ErrVersionMismatch
+				break
+			}
+			t := *prev
+			p.applyTo(&t)
+			t.UpdatedAt = now
+			t.Version++
+			staged[op.ID] = &t
+			res.Task = &t
+
+		case BatchDelete:
+			prev, ok := lookup(op.ID)
+			if !ok {
+				res.Err = ErrNotFound
+				break
+			}
+			if op.IfVersion != 0 && op.IfVersion != prev.Version {
+				res.Err = ErrVersionMismatch
+				break
+			}
+			staged[op.ID] = nil
+
+		default:
+			res.Err = ErrInvalidBatchOp
+		}
+
+		if res.Err != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		for i := range results {
+			results[i].Task = nil
+		}
+		return results, nil, 0, ErrBatchAborted
+	}
+	return results, staged, nextID, nil
+}
@@ -1,35 +1,72 @@
 package storage
 
 import (
-	"errors"
-	"fmt"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 )
 
-type Task struct {
-	ID    int64  `json:"id"`
-	Title string `json:"title"`
-	Done  bool   `json:"done"`
-}
-
 type MemoryStore struct {
 	mu    sync.RWMutex
 	auto  int64
 	tasks map[int64]*Task
+	order []int64 // id задач по возрастанию — индекс для стабильной выдачи
+
+	// sorted — задачи, упорядоченные по ListQuery.less для каждой сортировки,
+	// кроме id: List идёт по готовому индексу, а не сортирует выборку заново.
+	sorted map[sortKey][]*Task
+}
+
+type sortKey struct {
+	field SortField
+	desc  bool
 }
 
+// indexedSorts — сортировки, для которых ведётся отдельный индекс.
+var indexedSorts = []SortField{SortByTitle, SortByCreated, SortByDue, SortByPriority}
+
 func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{
-		tasks: make(map[int64]*Task),
+	s := &MemoryStore{
+		tasks:  make(map[int64]*Task),
+		sorted: make(map[sortKey][]*Task),
 	}
+	for _, f := range indexedSorts {
+		s.sorted[sortKey{f, false}] = nil
+		s.sorted[sortKey{f, true}] = nil
+	}
+	return s
 }
 
-func (s *MemoryStore) Create(title string) *Task {
+func (s *MemoryStore) Create(payload TaskCreatePayload) (*Task, error) {
+	if err := payload.Normalize(); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.auto++
-	t := &Task{ID: s.auto, Title: title, Done: false}
-	s.tasks[t.ID] = t
+	t := newTask(s.auto, payload, time.Now().UTC())
+	s.setLocked(t.ID, t)
+	return t, nil
+}
+
+// newTask собирает задачу из уже проверенного payload.
+func newTask(id int64, payload TaskCreatePayload, now time.Time) *Task {
+	t := &Task{
+		ID:          id,
+		Title:       payload.Title,
+		Description: payload.Description,
+		Priority:    payload.Priority,
+		Tags:        payload.Tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+	}
+	if payload.DueDate != nil {
+		due := payload.DueDate.UTC()
+		t.DueDate = &due
+	}
 	return t
 }
 
@@ -38,41 +75,191 @@ func (s *MemoryStore) Get(id int64) (*Task, error) {
 	defer s.mu.RUnlock()
 	t, ok := s.tasks[id]
 	if !ok {
-		return nil, errors.New("not found")
+		return nil, ErrNotFound
 	}
 	return t, nil
 }
 
-func (s *MemoryStore) List() []*Task {
+// List возвращает страницу задач, проходя один раз по индексу нужной сортировки:
+// задачи не копируются и не сортируются, на страницу попадают только подходящие.
+func (s *MemoryStore) List(q ListQuery) (ListPage, error) {
+	if q.Sort == "" {
+		q.Sort = SortByID
+	}
+	var pivot *Task
+	if q.Cursor != "" {
+		var err error
+		if pivot, err = decodeCursor(&q); err != nil {
+			return ListPage{}, err
+		}
+		q.Offset = 0
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	out := make([]*Task, 0, len(s.tasks))
-	for _, t := range s.tasks {
-		out = append(out, t)
+
+	if q.Sort == SortByID {
+		n := len(s.order)
+		return s.walk(&q, pivot, n, func(i int) *Task {
+			if q.Desc {
+				i = n - 1 - i
+			}
+			return s.tasks[s.order[i]]
+		}), nil
 	}
-	return out
+	idx := s.sorted[sortKey{q.Sort, q.Desc}]
+	return s.walk(&q, pivot, len(idx), func(i int) *Task { return idx[i] }), nil
 }
 
-type TaskUpdatePayload struct {
-	Done bool `json:"done"`
+// walk перебирает n задач в порядке выдачи (at(i) — i-я по порядку) и собирает страницу.
+func (s *MemoryStore) walk(q *ListQuery, pivot *Task, n int, at func(int) *Task) ListPage {
+	page := ListPage{Items: make([]*Task, 0)}
+	skipped := 0
+	var last *Task
+	more := false
+
+	for i := 0; i < n; i++ {
+		t := at(i)
+		if !q.matches(t) {
+			continue
+		}
+		page.Total++
+
+		if pivot != nil && !q.less(pivot, t) {
+			continue
+		}
+		if skipped < q.Offset {
+			skipped++
+			continue
+		}
+		if q.Limit > 0 && len(page.Items) == q.Limit {
+			more = true
+			continue
+		}
+		page.Items = append(page.Items, t)
+		last = t
+	}
+	if more && last != nil {
+		page.NextCursor = encodeCursor(q, last)
+	}
+	return page
 }
 
-func (s *MemoryStore) Update(id int64, payload TaskUpdatePayload) *Task {
+// Update применяет частичное обновление. Задача не меняется на месте:
+// в хранилище кладётся новая копия, чтобы ранее выданные указатели оставались согласованными.
+func (s *MemoryStore) Update(id int64, payload TaskUpdatePayload) (*Task, error) {
+	if err := payload.Normalize(); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	prev, ok := s.tasks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if payload.IfVersion != 0 && payload.IfVersion != prev.Version {
+		return nil, ErrVersionMismatch
+	}
+
+	t := *prev
+	payload.applyTo(&t)
+	t.UpdatedAt = time.Now().UTC()
+	t.Version++
+	s.setLocked(id, &t)
+	return &t, nil
+}
+
+func (s *MemoryStore) Delete(id int64, ifVersion int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	t, ok := s.tasks[id]
 	if !ok {
-		return nil
+		return ErrNotFound
 	}
-	fmt.Print(t.ID, t.Done)
+	if ifVersion != 0 && ifVersion != t.Version {
+		return ErrVersionMismatch
+	}
+	s.setLocked(id, nil)
+	return nil
+}
 
-	t.Done = payload.Done
-	return t
+// put кладёт задачу как есть и подтягивает счётчик id (используется при восстановлении из журнала).
+func (s *MemoryStore) put(t Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// записи журнала до появления версий
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	s.setLocked(t.ID, &t)
+	if t.ID > s.auto {
+		s.auto = t.ID
+	}
 }
 
-func (s *MemoryStore) Delete(id int64) {
+// bumpSeq гарантирует, что следующий id будет больше seq.
+func (s *MemoryStore) bumpSeq(seq int64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	delete(s.tasks, id)
+	if seq > s.auto {
+		s.auto = seq
+	}
+}
+
+func (s *MemoryStore) seq() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.auto
+}
+
+// all возвращает все задачи по возрастанию id.
+func (s *MemoryStore) all() []*Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*Task, 0, len(s.order))
+	for _, id := range s.order {
+		out = append(out, s.tasks[id])
+	}
+	return out
+}
+
+func (s *MemoryStore) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tasks)
+}
+
+// setLocked кладёт задачу под id (nil — удаляет) и обновляет все индексы.
+func (s *MemoryStore) setLocked(id int64, t *Task) {
+	prev, exists := s.tasks[id]
+	if t == nil {
+		delete(s.tasks, id)
+	} else {
+		s.tasks[id] = t
+	}
+
+	i, found := slices.BinarySearch(s.order, id)
+	switch {
+	case t == nil && found:
+		s.order = slices.Delete(s.order, i, i+1)
+	case t != nil && !found:
+		s.order = slices.Insert(s.order, i, id)
+	}
+
+	for key, idx := range s.sorted {
+		q := ListQuery{Sort: key.field, Desc: key.desc}
+		if exists {
+			j := sort.Search(len(idx), func(j int) bool { return !q.less(idx[j], prev) })
+			if j < len(idx) && idx[j].ID == id {
+				idx = slices.Delete(idx, j, j+1)
+			}
+		}
+		if t != nil {
+			j := sort.Search(len(idx), func(j int) bool { return q.less(t, idx[j]) })
+			idx = slices.Insert(idx, j, t)
+		}
+		s.sorted[key] = idx
+	}
 }
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotFound — задачи с запрошенным id нет в хранилище.
+	ErrNotFound = errors.New("task not found")
+	// ErrVersionMismatch — задачу успели изменить после того, как клиент её прочитал.
+	ErrVersionMismatch = errors.New("task version mismatch")
+)
+
+// ValidationError — входные данные задачи не прошли проверку.
+type ValidationError struct {
+	Field string
+	Msg   string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+// Rank — числовой вес приоритета, чем выше, тем важнее задача.
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return 0
+}
+
+func (p Priority) Valid() bool {
+	return p.Rank() > 0
+}
+
+const (
+	titleMinLen       = 3
+	titleMaxLen       = 140
+	descriptionMaxLen = 2000
+	tagsMax           = 10
+	tagMaxLen         = 32
+)
+
+type Task struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    Priority   `json:"priority"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Tags        []string   `json:"tags"`
+	Done        bool       `json:"done"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	// Version начинается с 1 и растёт при каждом изменении задачи.
+	Version int64 `json:"version"`
+}
+
+// TaskCreatePayload — поля новой задачи; пустой Priority означает medium.
+type TaskCreatePayload struct {
+	Title       string
+	Description string
+	Priority    Priority
+	DueDate     *time.Time
+	Tags        []string
+}
+
+// Normalize приводит поля к каноничному виду и проверяет их.
+func (p *TaskCreatePayload) Normalize() error {
+	p.Title = strings.TrimSpace(p.Title)
+	if err := validateTitle(p.Title); err != nil {
+		return err
+	}
+	p.Description = strings.TrimSpace(p.Description)
+	if err := validateDescription(p.Description); err != nil {
+		return err
+	}
+	if p.Priority == "" {
+		p.Priority = PriorityMedium
+	}
+	if !p.Priority.Valid() {
+		return invalidPriority()
+	}
+	tags, err := normalizeTags(p.Tags)
+	if err != nil {
+		return err
+	}
+	p.Tags = tags
+	return nil
+}
+
+// TaskUpdatePayload — частичное обновление: nil-поля не трогают задачу.
+// Чтобы убрать срок выполнения, нужно выставить ClearDueDate.
+// Ненулевой IfVersion применяет обновление, только если версия задачи совпадает.
+type TaskUpdatePayload struct {
+	IfVersion int64
+
+	Title        *string
+	Description  *string
+	Priority     *Priority
+	DueDate      *time.Time
+	ClearDueDate bool
+	Tags         *[]string
+	Done         *bool
+}
+
+// Normalize приводит заданные поля к каноничному виду и проверяет их.
+func (p *TaskUpdatePayload) Normalize() error {
+	if p.Title != nil {
+		title := strings.TrimSpace(*p.Title)
+		if err := validateTitle(title); err != nil {
+			return err
+		}
+		p.Title = &title
+	}
+	if p.Description != nil {
+		desc := strings.TrimSpace(*p.Description)
+		if err := validateDescription(desc); err != nil {
+			return err
+		}
+		p.Description = &desc
+	}
+	if p.Priority != nil && !p.Priority.Valid() {
+		return invalidPriority()
+	}
+	if p.Tags != nil {
+		tags, err := normalizeTags(*p.Tags)
+		if err != nil {
+			return err
+		}
+		p.Tags = &tags
+	}
+	return nil
+}
+
+// applyTo переносит заданные поля на задачу.
+func (p TaskUpdatePayload) applyTo(t *Task) {
+	if p.Title != nil {
+		t.Title = *p.Title
+	}
+	if p.Description != nil {
+		t.Description = *p.Description
+	}
+	if p.Priority != nil {
+		t.Priority = *p.Priority
+	}
+	if p.ClearDueDate {
+		t.DueDate = nil
+	} else if p.DueDate != nil {
+		due := p.DueDate.UTC()
+		t.DueDate = &due
+	}
+	if p.Tags != nil {
+		t.Tags = append([]string(nil), (*p.Tags)...)
+	}
+	if p.Done != nil {
+		t.Done = *p.Done
+	}
+}
+
+func validateTitle(title string) error {
+	if title == "" {
+		return &ValidationError{Field: "title", Msg: "is required"}
+	}
+	if n := utf8.RuneCountInString(title); n < titleMinLen || n > titleMaxLen {
+		return &ValidationError{Field: "title", Msg: fmt.Sprintf("size should be between %d and %d", titleMinLen, titleMaxLen)}
+	}
+	return nil
+}
+
+func validateDescription(desc string) error {
+	if utf8.RuneCountInString(desc) > descriptionMaxLen {
+		return &ValidationError{Field: "description", Msg: fmt.Sprintf("must be at most %d characters", descriptionMaxLen)}
+	}
+	return nil
+}
+
+func invalidPriority() error {
+	return &ValidationError{Field: "priority", Msg: "must be one of low, medium, high"}
+}
+
+// normalizeTags обрезает пробелы, приводит к нижнему регистру и убирает дубликаты.
+func normalizeTags(in []string) ([]string, error) {
+	out := make([]string, 0, len(in))
+	seen := make(map[string]struct{}, len(in))
+	for _, tag := range in {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > tagMaxLen {
+			return nil, &ValidationError{Field: "tags", Msg: fmt.Sprintf("tag %q is longer than %d characters", tag, tagMaxLen)}
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	if len(out) > tagsMax {
+		return nil, &ValidationError{Field: "tags", Msg: fmt.Sprintf("at most %d tags allowed", tagsMax)}
+	}
+	return out, nil
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor — курсор повреждён или выдан для другой сортировки.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type SortField string
+
+const (
+	SortByID       SortField = "id"
+	SortByTitle    SortField = "title"
+	SortByCreated  SortField = "created"
+	SortByDue      SortField = "due"
+	SortByPriority SortField = "priority"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortByID, SortByTitle, SortByCreated, SortByDue, SortByPriority:
+		return true
+	}
+	return false
+}
+
+// ListQuery — параметры выборки задач. Нулевое значение означает
+// все задачи по возрастанию id без ограничения количества.
+type ListQuery struct {
+	Search string   // подстрока в названии, без учёта регистра
+	Done   *bool    // nil — не фильтровать по статусу
+	Tags   []string // задача должна содержать все перечисленные теги
+
+	Sort SortField
+	Desc bool
+
+	Limit  int    // 0 — без ограничения
+	Offset int    // игнорируется, если задан Cursor
+	Cursor string // продолжение выборки после последней задачи предыдущей страницы
+}
+
+// ListPage — одна страница выборки.
+type ListPage struct {
+	Items      []*Task
+	Total      int    // сколько задач подходит под фильтры без учёта пагинации
+	NextCursor string // пусто, если дальше задач нет
+}
+
+// matches проверяет задачу на соответствие фильтрам запроса.
+func (q *ListQuery) matches(t *Task) bool {
+	if q.Done != nil && t.Done != *q.Done {
+		return false
+	}
+	if q.Search != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(q.Search)) {
+		return false
+	}
+	for _, want := range q.Tags {
+		found := false
+		for _, tag := range t.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// less задаёт порядок выдачи; при равенстве ключей порядок определяет id,
+// поэтому он стабилен между запросами. Задачи без срока всегда идут последними.
+func (q *ListQuery) less(a, b *Task) bool {
+	if q.Sort == SortByDue && (a.DueDate == nil) != (b.DueDate == nil) {
+		return b.DueDate == nil
+	}
+	if c := compareKey(q.Sort, a, b); c != 0 {
+		if q.Desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	if q.Desc {
+		return a.ID > b.ID
+	}
+	return a.ID < b.ID
+}
+
+func compareKey(field SortField, a, b *Task) int {
+	switch field {
+	case SortByTitle:
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case SortByCreated:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case SortByDue:
+		if a.DueDate == nil || b.DueDate == nil {
+			return 0
+		}
+		return a.DueDate.Compare(*b.DueDate)
+	case SortByPriority:
+		return a.Priority.Rank() - b.Priority.Rank()
+	}
+	return 0
+}
+
+// cursor — содержимое непрозрачного курсора: ключ сортировки и id последней выданной задачи.
+type cursor struct {
+	Sort SortField `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	ID   int64     `json:"i"`
+	Key  string    `json:"k,omitempty"`
+}
+
+func encodeCursor(q *ListQuery, t *Task) string {
+	c := cursor{Sort: q.Sort, Desc: q.Desc, ID: t.ID}
+	switch q.Sort {
+	case SortByTitle:
+		c.Key = t.Title
+	case SortByCreated:
+		c.Key = t.CreatedAt.Format(time.RFC3339Nano)
+	case SortByDue:
+		if t.DueDate != nil {
+			c.Key = t.DueDate.Format(time.RFC3339Nano)
+		}
+	case SortByPriority:
+		c.Key = string(t.Priority)
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor восстанавливает из курсора опорную задачу, после которой продолжается выдача.
+func decodeCursor(q *ListQuery) (*Task, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != q.Sort || c.Desc != q.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	pivot := &Task{ID: c.ID}
+	switch c.Sort {
+	case SortByTitle:
+		pivot.Title = c.Key
+	case SortByCreated:
+		ts, err := time.Parse(time.RFC3339Nano, c.Key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		pivot.CreatedAt = ts
+	case SortByDue:
+		if c.Key != "" {
+			ts, err := time.Parse(time.RFC3339Nano, c.Key)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			pivot.DueDate = &ts
+		}
+	case SortByPriority:
+		pivot.Priority = Priority(c.Key)
+	}
+	return pivot, nil
+}
@@ -0,0 +1,17 @@
+package storage
+
+// TaskStore — общий интерфейс хранилища задач, от которого зависят обработчики.
+type TaskStore interface {
+	Create(payload TaskCreatePayload) (*Task, error)
+	Get(id int64) (*Task, error)
+	List(q ListQuery) (ListPage, error)
+	Update(id int64, payload TaskUpdatePayload) (*Task, error)
+	// Delete с ненулевым ifVersion удаляет задачу, только если её версия совпадает.
+	Delete(id int64, ifVersion int64) error
+	Batch(ops []BatchOp) ([]BatchResult, error)
+}
+
+var (
+	_ TaskStore = (*MemoryStore)(nil)
+	_ TaskStore = (*WALStore)(nil)
+)
@@ -0,0 +1,393 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultCompactThreshold — сколько записей должно накопиться в журнале,
+// прежде чем имеет смысл его переписывать.
+const defaultCompactThreshold = 1000
+
+const (
+	walOpPut   = "put"
+	walOpDel   = "del"
+	walOpSeq   = "seq"
+	walOpBatch = "batch"
+)
+
+// walRecord — одна строка журнала (JSON Lines). Пакет пишется одной строкой,
+// поэтому при обрыве записи он отбрасывается целиком.
+type walRecord struct {
+	Op    string      `json:"op"`
+	Task  *Task       `json:"task,omitempty"`
+	ID    int64       `json:"id,omitempty"`
+	Batch []walRecord `json:"batch,omitempty"`
+}
+
+// WALStore — хранилище с журналом упреждающей записи: данные живут в памяти,
+// а каждое изменение дописывается в файл и синхронизируется на диск.
+// При запуске журнал проигрывается, а по мере роста — сжимается до снимка.
+type WALStore struct {
+	mu   sync.Mutex
+	mem  *MemoryStore
+	path string
+	file walFile
+
+	records   int
+	threshold int
+}
+
+// walFile — то, что журналу нужно от открытого файла; в тестах подменяется, чтобы сымитировать сбой записи.
+type walFile interface {
+	io.WriteSeeker
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+// NewWALStore открывает (или создаёт) журнал по пути path и восстанавливает из него состояние.
+func NewWALStore(path string) (*WALStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("wal: create dir: %w", err)
+		}
+	}
+
+	s := &WALStore{
+		mem:       NewMemoryStore(),
+		path:      path,
+		threshold: defaultCompactThreshold,
+	}
+
+	torn, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("wal: open: %w", err)
+	}
+	s.file = f
+
+	// недописанную последнюю строку (падение посреди записи) убираем сразу
+	if torn || s.needsCompaction() {
+		if err := s.compactLocked(); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *WALStore) Create(payload TaskCreatePayload) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.mem.Create(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.appendLocked(walRecord{Op: walOpPut, Task: t}); err != nil {
+		_ = s.mem.Delete(t.ID, 0)
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *WALStore) Get(id int64) (*Task, error) {
+	return s.mem.Get(id)
+}
+
+func (s *WALStore) List(q ListQuery) (ListPage, error) {
+	return s.mem.List(q)
+}
+
+func (s *WALStore) Update(id int64, payload TaskUpdatePayload) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, err := s.mem.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.mem.Update(id, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.appendLocked(walRecord{Op: walOpPut, Task: t}); err != nil {
+		s.mem.put(*prev)
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *WALStore) Delete(id int64, ifVersion int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, err := s.mem.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mem.Delete(id, ifVersion); err != nil {
+		return err
+	}
+	if err := s.appendLocked(walRecord{Op: walOpDel, ID: id}); err != nil {
+		s.mem.put(*prev)
+		return err
+	}
+	return nil
+}
+
+func (s *WALStore) Batch(ops []BatchOp) ([]BatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// прежние версии задач нужны, чтобы откатить память, если журнал не записался
+	prev := make(map[int64]Task)
+	for _, op := range ops {
+		if op.Op == BatchCreate {
+			continue
+		}
+		if t, err := s.mem.Get(op.ID); err == nil {
+			if _, seen := prev[op.ID]; !seen {
+				prev[op.ID] = *t
+			}
+		}
+	}
+
+	results, err := s.mem.Batch(ops)
+	if err != nil {
+		return results, err
+	}
+
+	rec := walRecord{Op: walOpBatch, Batch: make([]walRecord, 0, len(results))}
+	for _, res := range results {
+		if res.Op == BatchDelete {
+			rec.Batch = append(rec.Batch, walRecord{Op: walOpDel, ID: res.ID})
+		} else {
+			rec.Batch = append(rec.Batch, walRecord{Op: walOpPut, Task: res.Task})
+		}
+	}
+	if err := s.appendLocked(rec); err != nil {
+		for _, res := range results {
+			if res.Op == BatchCreate {
+				_ = s.mem.Delete(res.ID, 0)
+			}
+		}
+		for _, t := range prev {
+			s.mem.put(t)
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// Compact переписывает журнал в виде снимка текущего состояния.
+func (s *WALStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compactLocked()
+}
+
+func (s *WALStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// replay читает журнал и применяет записи к памяти.
+// Возвращает true, если последняя строка оказалась недописанной.
+func (s *WALStore) replay() (bool, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("wal: open for replay: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	line := 0
+	for {
+		raw, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(raw)) == 0 {
+				return false, nil
+			}
+			// хвост без перевода строки — запись не успела завершиться
+			var rec walRecord
+			if json.Unmarshal(raw, &rec) != nil {
+				return true, nil
+			}
+			s.apply(rec)
+			s.records++
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("wal: read: %w", err)
+		}
+		line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		var rec walRecord
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return false, fmt.Errorf("wal: corrupt record at line %d: %w", line, err)
+		}
+		s.apply(rec)
+		s.records++
+	}
+}
+
+func (s *WALStore) apply(rec walRecord) {
+	switch rec.Op {
+	case walOpPut:
+		if rec.Task != nil {
+			s.mem.put(*rec.Task)
+		}
+	case walOpDel:
+		_ = s.mem.Delete(rec.ID, 0)
+	case walOpSeq:
+		s.mem.bumpSeq(rec.ID)
+	case walOpBatch:
+		for _, r := range rec.Batch {
+			s.apply(r)
+		}
+	}
+}
+
+func (s *WALStore) appendLocked(rec walRecord) error {
+	if s.file == nil {
+		return errors.New("wal: store is closed")
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("wal: encode: %w", err)
+	}
+	b = append(b, '\n')
+
+	// запоминаем конец журнала, чтобы при сбое не оставить в нём обрывок записи
+	off, err := s.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("wal: seek: %w", err)
+	}
+	if _, err := s.file.Write(b); err != nil {
+		return s.rollbackLocked(off, fmt.Errorf("wal: write: %w", err))
+	}
+	if err := s.file.Sync(); err != nil {
+		return s.rollbackLocked(off, fmt.Errorf("wal: sync: %w", err))
+	}
+	s.records++
+
+	if s.needsCompaction() {
+		// журнал уже надёжно записан, поэтому ошибка сжатия не отменяет операцию
+		_ = s.compactLocked()
+	}
+	return nil
+}
+
+// rollbackLocked обрезает журнал до off после неудачной дозаписи. Если обрезать
+// не удалось, журнал закрывается: следующая запись легла бы после мусора.
+func (s *WALStore) rollbackLocked(off int64, cause error) error {
+	err := s.file.Truncate(off)
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		_ = s.file.Close()
+		s.file = nil
+		return errors.Join(cause, fmt.Errorf("wal: truncate: %w", err))
+	}
+	return cause
+}
+
+func (s *WALStore) needsCompaction() bool {
+	return s.records >= s.threshold && s.records > 2*s.mem.count()
+}
+
+// compactLocked пишет снимок во временный файл и атомарно подменяет им журнал.
+func (s *WALStore) compactLocked() error {
+	tasks := s.mem.all()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("wal: compact: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(walRecord{Op: walOpSeq, ID: s.mem.seq()}); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("wal: compact: %w", err)
+	}
+	for _, t := range tasks {
+		if err := enc.Encode(walRecord{Op: walOpPut, Task: t}); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("wal: compact: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("wal: compact: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("wal: compact: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("wal: compact: %w", err)
+	}
+
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return s.reopen(fmt.Errorf("wal: compact: %w", err))
+	}
+	syncDir(filepath.Dir(s.path))
+
+	s.records = len(tasks) + 1
+	return s.reopen(nil)
+}
+
+// reopen заново открывает журнал на дозапись; cause возвращается, если открыть удалось.
+func (s *WALStore) reopen(cause error) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("wal: reopen: %w", err)
+	}
+	s.file = f
+	return cause
+}
+
+// syncDir фиксирует на диске переименование файла внутри каталога.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
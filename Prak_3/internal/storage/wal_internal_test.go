@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingFile дописывает только половину данных и возвращает ошибку,
+// как при переполнении диска посреди записи.
+type failingFile struct {
+	*os.File
+}
+
+func (f failingFile) Write(b []byte) (int, error) {
+	n, _ := f.File.Write(b[:len(b)/2])
+	return n, errors.New("disk full")
+}
+
+func TestWALStore_FailedWriteLeavesNoPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.wal")
+
+	s, err := NewWALStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := s.Create(TaskCreatePayload{Title: "Buy milk"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	good := s.file
+	s.file = failingFile{good.(*os.File)}
+	if _, err := s.Create(TaskCreatePayload{Title: "Lost"}); err == nil {
+		t.Fatal("expected create to fail")
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Fatalf("journal changed after failed write:\nbefore %q\nafter  %q", before, after)
+	}
+
+	// после отката журнал остаётся рабочим: новая запись не склеивается с обрывком
+	s.file = good
+	if _, err := s.Create(TaskCreatePayload{Title: "Write code"}); err != nil {
+		t.Fatalf("create after failure: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	s, err = NewWALStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	page, _ := s.List(ListQuery{})
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 tasks after replay, got %d", len(page.Items))
+	}
+	for _, task := range page.Items {
+		if task.Title == "Lost" {
+			t.Fatalf("failed create was replayed: %+v", task)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"prak3/internal/api"
+	"prak3/internal/storage"
+	"testing"
+)
+
+type batchResult struct {
+	Error   string `json:"error"`
+	Results []struct {
+		Index  int    `json:"index"`
+		Status int    `json:"status"`
+		ID     int64  `json:"id"`
+		Error  string `json:"error"`
+	} `json:"results"`
+}
+
+func doBatch(t *testing.T, h *api.Handlers, body string) (*httptest.ResponseRecorder, batchResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/tasks:batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.BatchTasks(w, req)
+
+	var res batchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	return w, res
+}
+
+func TestBatchTasks_AppliesAll(t *testing.T) {
+	store := storage.NewMemoryStore()
+	existing, _ := store.Create(storage.TaskCreatePayload{Title: "Old task"})
+	h := api.NewHandlers(store)
+
+	w, res := doBatch(t, h, `{"operations":[
+		{"op":"create","task":{"title":"Imported one"}},
+		{"op":"update","id":1,"task":{"done":true}},
+		{"op":"create","task":{"title":"Imported two"}}
+	]}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(res.Results) != 3 || res.Results[0].Status != http.StatusCreated || res.Results[1].Status != http.StatusOK {
+		t.Fatalf("unexpected results: %+v", res.Results)
+	}
+	if got, _ := store.Get(existing.ID); !got.Done {
+		t.Error("update was not applied")
+	}
+	if page, _ := store.List(storage.ListQuery{}); page.Total != 3 {
+		t.Errorf("expected 3 tasks, got %d", page.Total)
+	}
+}
+
+func TestBatchTasks_RollsBackOnFailure(t *testing.T) {
+	store := storage.NewMemoryStore()
+	_, _ = store.Create(storage.TaskCreatePayload{Title: "Keep me"})
+	h := api.NewHandlers(store)
+
+	w, res := doBatch(t, h, `{"operations":[
+		{"op":"create","task":{"title":"Would be created"}},
+		{"op":"delete","id":1},
+		{"op":"update","id":1,"task":{"done":true}},
+		{"op":"create","task":{"title":"x"}}
+	]}`)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+	want := []int{http.StatusFailedDependency, http.StatusFailedDependency, http.StatusNotFound, http.StatusUnprocessableEntity}
+	for i, status := range want {
+		if res.Results[i].Status != status {
+			t.Errorf("item %d: expected status %d, got %d (%s)", i, status, res.Results[i].Status, res.Results[i].Error)
+		}
+	}
+
+	page, _ := store.List(storage.ListQuery{})
+	if page.Total != 1 || page.Items[0].Title != "Keep me" {
+		t.Fatalf("store changed after rolled back batch: %+v", page.Items)
+	}
+	if next, _ := store.Create(storage.TaskCreatePayload{Title: "After batch"}); next.ID != 2 {
+		t.Errorf("rolled back batch consumed ids, next id %d", next.ID)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"prak3/internal/api"
+	"testing"
+	"time"
+)
+
+func corsHandler() http.Handler {
+	policy := api.CORSPolicy{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPatch},
+		AllowedHeaders:   []string{"Content-Type"},
+		ExposedHeaders:   []string{"X-Total-Count"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	}
+	return api.WithCORS(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+}
+
+func TestCORS_PreflightAllowedWildcardSubdomain(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/tasks/1", nil)
+	req.Header.Set("Origin", "https://team.example.org")
+	req.Header.Set("Access-Control-Request-Method", "PATCH")
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+	w := httptest.NewRecorder()
+
+	corsHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://team.example.org" {
+		t.Errorf("expected echoed origin, got %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Error("expected credentials header")
+	}
+	if w.Header().Get("Access-Control-Max-Age") != "60" {
+		t.Errorf("expected max-age 60, got %q", w.Header().Get("Access-Control-Max-Age"))
+	}
+}
+
+func TestCORS_UnknownOriginPreflightRejected(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/tasks", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+
+	corsHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("unexpected allow-origin for unknown origin")
+	}
+}
+
+func TestCORS_PlainOptionsPassesThrough(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/tasks", nil)
+	w := httptest.NewRecorder()
+
+	corsHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected request to reach handler, got %d", w.Code)
+	}
+	if w.Header().Get("Vary") != "Origin" {
+		t.Errorf("expected Vary: Origin, got %q", w.Header().Get("Vary"))
+	}
+}
+
+func TestCORS_ActualRequestExposesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+
+	corsHandler().ServeHTTP(w, req)
+
+	if w.Header().Get("Access-Control-Expose-Headers") != "X-Total-Count" {
+		t.Errorf("expected exposed headers, got %q", w.Header().Get("Access-Control-Expose-Headers"))
+	}
+}
+
+func TestCORSPolicy_WildcardWithCredentialsInvalid(t *testing.T) {
+	p := api.DefaultCORSPolicy()
+	p.AllowCredentials = true
+	if err := p.Validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
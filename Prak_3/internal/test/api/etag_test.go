@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"prak3/internal/api"
+	"prak3/internal/storage"
+	"testing"
+)
+
+func TestGetTask_ETagAndIfNoneMatch(t *testing.T) {
+	store := storage.NewMemoryStore()
+	_, _ = store.Create(storage.TaskCreatePayload{Title: "Shared board"})
+	h := api.NewHandlers(store)
+
+	w := httptest.NewRecorder()
+	h.GetTask(w, httptest.NewRequest(http.MethodGet, "/tasks/1", nil))
+	tag := w.Header().Get("ETag")
+	if tag != `"1"` {
+		t.Fatalf("expected ETag \"1\", got %q", tag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+	req.Header.Set("If-None-Match", tag)
+	w = httptest.NewRecorder()
+	h.GetTask(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected empty 304, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateTask_IfMatchPreventsLostUpdate(t *testing.T) {
+	store := storage.NewMemoryStore()
+	_, _ = store.Create(storage.TaskCreatePayload{Title: "Shared board"})
+	h := api.NewHandlers(store)
+
+	patch := func(body, ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/tasks/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req)
+		return w
+	}
+
+	first := patch(`{"title":"Alice edit"}`, `"1"`)
+	if first.Code != http.StatusOK || first.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected 200 with ETag \"2\", got %d %q", first.Code, first.Header().Get("ETag"))
+	}
+
+	second := patch(`{"title":"Bob edit"}`, `"1"`)
+	if second.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for stale ETag, got %d", second.Code)
+	}
+	if got, _ := store.Get(1); got.Title != "Alice edit" {
+		t.Errorf("stale update overwrote task: %q", got.Title)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/tasks/1", nil)
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	h.DeleteTask(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for stale delete, got %d", w.Code)
+	}
+}
+
+func TestIfMatch_MissingTaskIsPreconditionFailed(t *testing.T) {
+	h := api.NewHandlers(storage.NewMemoryStore())
+
+	for _, ifMatch := range []string{`"1"`, `"1", "2"`, "*"} {
+		req := httptest.NewRequest(http.MethodPatch, "/tasks/7", bytes.NewBufferString(`{"title":"Ghost edit"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		w := httptest.NewRecorder()
+		h.UpdateTask(w, req)
+		if w.Code != http.StatusPreconditionFailed {
+			t.Errorf("PATCH with If-Match %s: expected 412, got %d", ifMatch, w.Code)
+		}
+
+		req = httptest.NewRequest(http.MethodDelete, "/tasks/7", nil)
+		req.Header.Set("If-Match", ifMatch)
+		w = httptest.NewRecorder()
+		h.DeleteTask(w, req)
+		if w.Code != http.StatusPreconditionFailed {
+			t.Errorf("DELETE with If-Match %s: expected 412, got %d", ifMatch, w.Code)
+		}
+	}
+
+	// без If-Match отсутствующая задача по-прежнему 404
+	w := httptest.NewRecorder()
+	h.DeleteTask(w, httptest.NewRequest(http.MethodDelete, "/tasks/7", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DELETE without If-Match: expected 404, got %d", w.Code)
+	}
+}
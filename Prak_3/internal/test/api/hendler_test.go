@@ -54,8 +54,8 @@ func TestCreateTask_TooShort(t *testing.T) {
 
 func TestListTasks_Filter(t *testing.T) {
 	store := storage.NewMemoryStore()
-	store.Create("Buy milk")
-	store.Create("Write code")
+	store.Create(storage.TaskCreatePayload{Title: "Buy milk"})
+	store.Create(storage.TaskCreatePayload{Title: "Write code"})
 
 	h := api.NewHandlers(store)
 	req := httptest.NewRequest(http.MethodGet, "/tasks?q=milk", nil)
@@ -76,3 +76,41 @@ func TestListTasks_Filter(t *testing.T) {
 		t.Errorf("filter failed, got %v", tasks)
 	}
 }
+
+func TestUpdateTask_PartialKeepsOmittedFields(t *testing.T) {
+	store := storage.NewMemoryStore()
+	created, _ := store.Create(storage.TaskCreatePayload{Title: "Write code", Priority: storage.PriorityHigh, Tags: []string{"work"}})
+	h := api.NewHandlers(store)
+
+	body := bytes.NewBufferString(`{"title":"Write more code"}`)
+	req := httptest.NewRequest(http.MethodPatch, "/tasks/1", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.UpdateTask(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	got, _ := store.Get(created.ID)
+	if got.Title != "Write more code" {
+		t.Errorf("title not updated, got %q", got.Title)
+	}
+	if got.Priority != storage.PriorityHigh || len(got.Tags) != 1 || got.Done {
+		t.Errorf("omitted fields changed: %+v", got)
+	}
+}
+
+func TestDeleteTask_Unknown(t *testing.T) {
+	h := api.NewHandlers(storage.NewMemoryStore())
+
+	req := httptest.NewRequest(http.MethodDelete, "/tasks/42", nil)
+	w := httptest.NewRecorder()
+
+	h.DeleteTask(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"prak3/internal/api"
+	"prak3/internal/storage"
+	"strings"
+	"testing"
+)
+
+func TestListTasks_InvalidQuery(t *testing.T) {
+	h := api.NewHandlers(storage.NewMemoryStore())
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"done=maybe", "done must be true or false"},
+		{"sort=color", "sort must be one of id, title, created, due, priority"},
+		{"order=up", "order must be asc or desc"},
+		{"limit=0", "limit must be between 1 and 500"},
+		{"limit=501", "limit must be between 1 and 500"},
+		{"offset=-1", "offset must be a non-negative integer"},
+		{"offset=2&cursor=abc", "offset and cursor cannot be combined"},
+		{"cursor=garbage", storage.ErrInvalidCursor.Error()},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/tasks?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		h.ListTasks(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", tt.query, w.Code)
+			continue
+		}
+		var body api.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", tt.query, err)
+		}
+		if body.Error != tt.want {
+			t.Errorf("%s: expected error %q, got %q", tt.query, tt.want, body.Error)
+		}
+	}
+}
+
+func TestListTasks_PaginationHeaders(t *testing.T) {
+	store := storage.NewMemoryStore()
+	for _, title := range []string{"one", "two", "three", "four", "five"} {
+		store.Create(storage.TaskCreatePayload{Title: title})
+	}
+	h := api.NewHandlers(store)
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks?limit=2&offset=2", nil)
+	w := httptest.NewRecorder()
+	h.ListTasks(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "5" {
+		t.Errorf("expected X-Total-Count 5, got %q", got)
+	}
+
+	links := parseLinks(t, w.Header().Get("Link"))
+	next, ok := links["next"]
+	if !ok {
+		t.Fatalf("expected rel=next in %q", w.Header().Get("Link"))
+	}
+	if next.Path != "/tasks" || next.Query().Get("cursor") == "" || next.Query().Has("offset") || next.Query().Get("limit") != "2" {
+		t.Errorf("unexpected next link %s", next)
+	}
+	prev, ok := links["prev"]
+	if !ok {
+		t.Fatalf("expected rel=prev in %q", w.Header().Get("Link"))
+	}
+	if prev.Query().Has("offset") || prev.Query().Get("limit") != "2" {
+		t.Errorf("unexpected prev link %s", prev)
+	}
+
+	// по ссылке next выдаётся последняя страница, и ссылок дальше нет
+	req = httptest.NewRequest(http.MethodGet, next.String(), nil)
+	w = httptest.NewRecorder()
+	h.ListTasks(w, req)
+
+	var tasks []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0]["title"] != "five" {
+		t.Errorf("expected last page [five], got %v", tasks)
+	}
+	if got := w.Header().Get("X-Total-Count"); got != "5" {
+		t.Errorf("expected X-Total-Count 5 on cursor page, got %q", got)
+	}
+	if got := w.Header().Get("Link"); got != "" {
+		t.Errorf("expected no Link on last page, got %q", got)
+	}
+}
+
+// parseLinks разбирает заголовок Link вида `<url>; rel="next", <url>; rel="prev"`.
+func parseLinks(t *testing.T, header string) map[string]*url.URL {
+	t.Helper()
+	links := make(map[string]*url.URL)
+	for _, part := range strings.Split(header, ", ") {
+		target, rel, ok := strings.Cut(part, "; rel=")
+		if !ok {
+			t.Fatalf("malformed Link part %q", part)
+		}
+		u, err := url.Parse(strings.Trim(target, "<>"))
+		if err != nil {
+			t.Fatalf("bad link %q: %v", target, err)
+		}
+		links[strings.Trim(rel, `"`)] = u
+	}
+	return links
+}
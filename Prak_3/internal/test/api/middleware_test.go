@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"prak3/internal/api"
+	"testing"
+)
+
+func TestLogging_StructuredFieldsWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	var seenID string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seenID = api.RequestIDFromContext(r.Context())
+		api.NotFound(w, "task not found")
+	})
+	handler := api.RequestID(api.Logging(logger)(mux))
+
+	req := httptest.NewRequest(http.MethodGet, "/tasks/7", nil)
+	req.Header.Set("X-Request-Id", "abc-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("X-Request-Id"); got != "abc-123" {
+		t.Fatalf("expected echoed request id, got %q", got)
+	}
+	if seenID != "abc-123" {
+		t.Fatalf("request id not in handler context, got %q", seenID)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v (%s)", err, buf.String())
+	}
+	if entry["route"] != "GET /tasks/{id}" || entry["status"] != float64(404) || entry["request_id"] != "abc-123" {
+		t.Errorf("unexpected log entry: %v", entry)
+	}
+	if entry["bytes"].(float64) != float64(w.Body.Len()) {
+		t.Errorf("expected bytes %d, got %v", w.Body.Len(), entry["bytes"])
+	}
+}
+
+func TestRequestID_GeneratedWhenMissing(t *testing.T) {
+	handler := api.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if id := w.Header().Get("X-Request-Id"); len(id) != 32 {
+		t.Errorf("expected generated 32-char id, got %q", id)
+	}
+}
+
+func TestRequestID_RejectsUnsafeClientID(t *testing.T) {
+	handler := api.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, id := range []string{"two words", "line\nbreak", "привет", string(bytes.Repeat([]byte("a"), 129))} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-Id", id)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("X-Request-Id"); got == id || len(got) != 32 {
+			t.Errorf("client id %q: expected a generated id, got %q", id, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"prak3/internal/api"
+	"testing"
+)
+
+func TestProbes_ReadyzFollowsState(t *testing.T) {
+	p := api.NewProbes()
+
+	w := httptest.NewRecorder()
+	p.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 before start, got %d", w.Code)
+	}
+
+	p.SetReady(true)
+	w = httptest.NewRecorder()
+	p.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 when ready, got %d", w.Code)
+	}
+
+	p.SetReady(false)
+	w = httptest.NewRecorder()
+	p.Livez(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected livez 200 while draining, got %d", w.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"prak3/internal/storage"
+	"testing"
+)
+
+func TestMemoryStore_ListCursorWalksAllPages(t *testing.T) {
+	s := storage.NewMemoryStore()
+	titles := []string{"delta", "alpha", "echo", "charlie", "bravo"}
+	for _, title := range titles {
+		_, _ = s.Create(storage.TaskCreatePayload{Title: title})
+	}
+
+	q := storage.ListQuery{Sort: storage.SortByTitle, Limit: 2}
+	var got []string
+	for i := 0; i < 5; i++ {
+		page, err := s.List(q)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if page.Total != len(titles) {
+			t.Fatalf("expected total %d, got %d", len(titles), page.Total)
+		}
+		for _, task := range page.Items {
+			got = append(got, task.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+	}
+
+	want := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestMemoryStore_ListFiltersAndDescByID(t *testing.T) {
+	s := storage.NewMemoryStore()
+	_, _ = s.Create(storage.TaskCreatePayload{Title: "one", Tags: []string{"work"}})
+	_, _ = s.Create(storage.TaskCreatePayload{Title: "two"})
+	_, _ = s.Create(storage.TaskCreatePayload{Title: "three", Tags: []string{"work", "home"}})
+
+	page, err := s.List(storage.ListQuery{Tags: []string{"work"}, Desc: true, Limit: 1})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 1 || page.Items[0].Title != "three" {
+		t.Fatalf("unexpected page: total=%d items=%v", page.Total, page.Items)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("expected next cursor")
+	}
+
+	page, _ = s.List(storage.ListQuery{Tags: []string{"work"}, Desc: true, Limit: 1, Cursor: page.NextCursor})
+	if len(page.Items) != 1 || page.Items[0].Title != "one" || page.NextCursor != "" {
+		t.Fatalf("unexpected second page: %v", page.Items)
+	}
+
+	if _, err := s.List(storage.ListQuery{Cursor: "garbage"}); err != storage.ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestMemoryStore_SortIndexFollowsChanges(t *testing.T) {
+	s := storage.NewMemoryStore()
+	low, high := storage.PriorityLow, storage.PriorityHigh
+	a, _ := s.Create(storage.TaskCreatePayload{Title: "alpha", Priority: storage.PriorityLow})
+	b, _ := s.Create(storage.TaskCreatePayload{Title: "bravo", Priority: storage.PriorityMedium})
+	c, _ := s.Create(storage.TaskCreatePayload{Title: "charlie", Priority: storage.PriorityHigh})
+
+	// alpha поднимается наверх, charlie опускается вниз, bravo удаляется
+	if _, err := s.Update(a.ID, storage.TaskUpdatePayload{Priority: &high}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if _, err := s.Update(c.ID, storage.TaskUpdatePayload{Priority: &low}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := s.Delete(b.ID, 0); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	d, _ := s.Create(storage.TaskCreatePayload{Title: "delta", Priority: storage.PriorityMedium})
+
+	tests := []struct {
+		q    storage.ListQuery
+		want []int64
+	}{
+		{storage.ListQuery{Sort: storage.SortByPriority, Desc: true}, []int64{a.ID, d.ID, c.ID}},
+		{storage.ListQuery{Sort: storage.SortByPriority}, []int64{c.ID, d.ID, a.ID}},
+		{storage.ListQuery{Sort: storage.SortByTitle, Desc: true}, []int64{d.ID, c.ID, a.ID}},
+	}
+	for _, tt := range tests {
+		page, err := s.List(tt.q)
+		if err != nil {
+			t.Fatalf("list %+v: %v", tt.q, err)
+		}
+		var got []int64
+		for _, task := range page.Items {
+			got = append(got, task.ID)
+		}
+		if len(got) != len(tt.want) || page.Total != len(tt.want) {
+			t.Fatalf("sort=%s desc=%v: expected %v, got %v", tt.q.Sort, tt.q.Desc, tt.want, got)
+		}
+		for i := range tt.want {
+			if got[i] != tt.want[i] {
+				t.Fatalf("sort=%s desc=%v: expected %v, got %v", tt.q.Sort, tt.q.Desc, tt.want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"prak3/internal/storage"
+	"testing"
+)
+
+func TestWALStore_ReplayAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.wal")
+
+	s, err := storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	first, _ := s.Create(storage.TaskCreatePayload{Title: "Buy milk"})
+	second, _ := s.Create(storage.TaskCreatePayload{Title: "Write code"})
+	done := true
+	if _, err := s.Update(first.ID, storage.TaskUpdatePayload{Done: &done}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := s.Delete(second.ID, 0); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	s, err = storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	page, _ := s.List(storage.ListQuery{})
+	tasks := page.Items
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task after replay, got %d", len(tasks))
+	}
+	if tasks[0].Title != "Buy milk" || !tasks[0].Done {
+		t.Errorf("unexpected task after replay: %+v", tasks[0])
+	}
+
+	third, _ := s.Create(storage.TaskCreatePayload{Title: "Read book"})
+	if third.ID != 3 {
+		t.Errorf("expected id 3 after replay, got %d", third.ID)
+	}
+}
+
+func TestWALStore_CompactKeepsSequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.wal")
+
+	s, err := storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	a, _ := s.Create(storage.TaskCreatePayload{Title: "first"})
+	b, _ := s.Create(storage.TaskCreatePayload{Title: "second"})
+	_ = s.Delete(b.ID, 0)
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	_ = s.Close()
+
+	s, err = storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.Get(a.ID); err != nil {
+		t.Errorf("task %d lost after compaction", a.ID)
+	}
+	c, _ := s.Create(storage.TaskCreatePayload{Title: "third"})
+	if c.ID != 3 {
+		t.Errorf("id of deleted task reused: got %d", c.ID)
+	}
+}
+
+func TestWALStore_TornTailIsDropped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.wal")
+
+	s, err := storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = s.Create(storage.TaskCreatePayload{Title: "kept"})
+	_ = s.Close()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"op":"put","task":{"id":2,"ti`)
+	_ = f.Close()
+
+	s, err = storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("reopen with torn tail: %v", err)
+	}
+	defer s.Close()
+
+	if page, _ := s.List(storage.ListQuery{}); page.Total != 1 {
+		t.Errorf("expected 1 task, got %d", page.Total)
+	}
+}
+
+func TestWALStore_BatchSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.wal")
+
+	s, err := storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	old, _ := s.Create(storage.TaskCreatePayload{Title: "to delete"})
+	_, err = s.Batch([]storage.BatchOp{
+		{Op: storage.BatchCreate, Create: &storage.TaskCreatePayload{Title: "batched"}},
+		{Op: storage.BatchDelete, ID: old.ID},
+	})
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	_ = s.Close()
+
+	s, err = storage.NewWALStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	page, _ := s.List(storage.ListQuery{})
+	if page.Total != 1 || page.Items[0].Title != "batched" {
+		t.Fatalf("unexpected tasks after replay: %+v", page.Items)
+	}
+}
@@ -0,0 +1,75 @@
+// Команда migrate переносит задачи между бэкендами хранилища:
+//
+//	go run ./cmd/migrate -from json:sources/tasks.json -to sqlite:sources/tasks.db
+//
+// id и даты задач сохраняются, счётчик id переносится тоже: id удалённых
+// в источнике задач не достанутся новым. Без -force в непустое хранилище ничего не пишется.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prak4/internal/task"
+)
+
+func main() {
+	from := flag.String("from", "", "источник: json:<path>, sqlite:<path> или bolt:<path>")
+	to := flag.String("to", "", "приёмник в том же формате")
+	force := flag.Bool("force", false, "писать в непустое хранилище, перезаписывая задачи с теми же id")
+	flag.Parse()
+
+	if *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := migrate(*from, *to, *force); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+}
+
+func migrate(from, to string, force bool) error {
+	srcBackend, srcPath := task.ParseLocation(from)
+	dstBackend, dstPath := task.ParseLocation(to)
+	if srcBackend == dstBackend && srcPath == dstPath {
+		return fmt.Errorf("source and destination are the same")
+	}
+
+	src, err := task.Open(srcBackend, srcPath)
+	if err != nil {
+		return fmt.Errorf("open source: %w", err)
+	}
+	defer src.Close()
+	dst, err := task.Open(dstBackend, dstPath)
+	if err != nil {
+		return fmt.Errorf("open destination: %w", err)
+	}
+	defer dst.Close()
+
+	tasks, err := src.List()
+	if err != nil {
+		return err
+	}
+	existing, err := dst.List()
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !force {
+		return fmt.Errorf("destination already has %d tasks, use -force to merge", len(existing))
+	}
+
+	if err := dst.Import(tasks); err != nil {
+		return err
+	}
+	lastID, err := src.LastID()
+	if err != nil {
+		return err
+	}
+	if err := dst.SetLastID(lastID); err != nil {
+		return err
+	}
+	log.Printf("migrated %d tasks from %s:%s to %s:%s", len(tasks), srcBackend, srcPath, dstBackend, dstPath)
+	return nil
+}
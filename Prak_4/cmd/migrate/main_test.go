@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"prak4/internal/task"
+)
+
+// Последняя задача источника удалена: её id не должен достаться новой задаче в приёмнике.
+func TestMigrateKeepsDeletedIDsReserved(t *testing.T) {
+	for _, backend := range []string{task.BackendJSON, task.BackendSQLite, task.BackendBolt} {
+		t.Run(backend, func(t *testing.T) {
+			dir := t.TempDir()
+			from := "bolt:" + filepath.Join(dir, "src.bolt")
+			if backend == task.BackendBolt {
+				from = "json:" + filepath.Join(dir, "src.json")
+			}
+			to := backend + ":" + filepath.Join(dir, "dst")
+
+			srcBackend, srcPath := task.ParseLocation(from)
+			src, err := task.Open(srcBackend, srcPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := src.Create("kept"); err != nil {
+				t.Fatal(err)
+			}
+			deleted, err := src.Create("deleted")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := src.Delete(deleted.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := src.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := migrate(from, to, false); err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+
+			dst, err := task.Open(backend, filepath.Join(dir, "dst"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer dst.Close()
+			next, err := dst.Create("new")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.ID <= deleted.ID {
+				t.Errorf("new task got id %d, deleted id in source was %d", next.ID, deleted.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultAuditPath — журнал аудита, если путь не задан в конфигурации.
+const DefaultAuditPath = "sources/audit.jsonl"
+
+// Действия в журнале аудита.
+const (
+	ActionCreated  = "created"
+	ActionUpdated  = "updated"
+	ActionDeleted  = "deleted"
+	ActionRestored = "restored"
+)
+
+// AuditEvent — одно изменение задачи. Revision нумерует изменения задачи с 1;
+// состояние задачи после ревизии — New (nil для удаления).
+type AuditEvent struct {
+	Revision  int       `json:"revision"`
+	TaskID    int64     `json:"task_id"`
+	Action    string    `json:"action"`
+	At        time.Time `json:"at"`
+	RequestID string    `json:"request_id,omitempty"`
+	Old       *Task     `json:"old,omitempty"`
+	New       *Task     `json:"new,omitempty"`
+}
+
+// AuditLog — журнал изменений задач: JSON Lines файл только на дописывание
+// и индекс по задачам в памяти. Не зависит от бэкенда хранилища.
+// Пустой путь — журнал только в памяти.
+type AuditLog struct {
+	mu     sync.RWMutex
+	file   auditFile
+	broken error // журнал не удалось вернуть в целое состояние после сбоя записи
+	byTask map[int64][]AuditEvent
+}
+
+// auditFile — то, что журналу нужно от открытого файла; в тестах подменяется.
+type auditFile interface {
+	io.WriteSeeker
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+func OpenAuditLog(path string) (*AuditLog, error) {
+	a := &AuditLog{byTask: make(map[int64][]AuditEvent)}
+	if path == "" {
+		return a, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	if err := a.load(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open audit log %s: %w", path, err)
+	}
+	a.file = f
+	return a, nil
+}
+
+// load читает журнал. Недописанная последняя строка (падение во время записи)
+// отрезается, повреждение в середине — ошибка.
+func (a *AuditLog) load(f *os.File) error {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	var good int
+	for off := 0; off < len(data); {
+		end := bytes.IndexByte(data[off:], '\n')
+		if end < 0 {
+			end = len(data) - off
+		}
+		line := data[off : off+end]
+		next := off + end + 1
+
+		var e AuditEvent
+		if len(bytes.TrimSpace(line)) > 0 {
+			if err := json.Unmarshal(line, &e); err != nil {
+				if next < len(data) {
+					return err
+				}
+				log.Printf("audit log: dropping torn last record: %v", err)
+				break
+			}
+			a.byTask[e.TaskID] = append(a.byTask[e.TaskID], e)
+		}
+		good = min(next, len(data))
+		off = next
+	}
+	if err := f.Truncate(int64(good)); err != nil {
+		return err
+	}
+	if _, err := f.Seek(int64(good), io.SeekStart); err != nil {
+		return err
+	}
+	if good > 0 && data[good-1] != '\n' {
+		_, err = f.Write([]byte{'\n'})
+	}
+	return err
+}
+
+// Append присваивает событию следующую ревизию задачи и записывает его на диск.
+func (a *AuditLog) Append(e AuditEvent) (AuditEvent, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e.Revision = len(a.byTask[e.TaskID]) + 1
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	if a.broken != nil {
+		return e, a.broken
+	}
+	if a.file != nil {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return e, err
+		}
+		off, err := a.file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return e, fmt.Errorf("append audit event: %w", err)
+		}
+		if _, err := a.file.Write(append(b, '\n')); err != nil {
+			return e, a.rollback(off, fmt.Errorf("append audit event: %w", err))
+		}
+		if err := a.file.Sync(); err != nil {
+			return e, a.rollback(off, fmt.Errorf("append audit event: %w", err))
+		}
+	}
+	a.byTask[e.TaskID] = append(a.byTask[e.TaskID], e)
+	return e, nil
+}
+
+// rollback обрезает журнал до off, чтобы в нём не осталось обрывка события.
+// Если и это не удалось, следующие события легли бы после мусора,
+// поэтому журнал перестаёт принимать записи.
+func (a *AuditLog) rollback(off int64, cause error) error {
+	err := a.file.Truncate(off)
+	if err == nil {
+		_, err = a.file.Seek(off, io.SeekStart)
+	}
+	if err != nil {
+		a.broken = errors.Join(cause, fmt.Errorf("truncate audit log: %w", err))
+		return a.broken
+	}
+	return cause
+}
+
+// History возвращает изменения задачи от старых к новым.
+func (a *AuditLog) History(taskID int64) []AuditEvent {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return append([]AuditEvent(nil), a.byTask[taskID]...)
+}
+
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	return a.file.Close()
+}
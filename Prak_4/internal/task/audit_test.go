@@ -0,0 +1,55 @@
+package task
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingFile дописывает половину данных и возвращает ошибку, как переполненный диск.
+type failingFile struct {
+	*os.File
+}
+
+func (f failingFile) Write(b []byte) (int, error) {
+	n, _ := f.File.Write(b[:len(b)/2])
+	return n, errors.New("disk full")
+}
+
+func TestAuditLogFailedAppendLeavesNoPartialEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	a, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Append(AuditEvent{TaskID: 1, Action: ActionCreated}); err != nil {
+		t.Fatal(err)
+	}
+
+	good := a.file
+	a.file = failingFile{good.(*os.File)}
+	if _, err := a.Append(AuditEvent{TaskID: 1, Action: ActionUpdated}); err == nil {
+		t.Fatal("expected append to fail")
+	}
+	a.file = good
+	if _, err := a.Append(AuditEvent{TaskID: 1, Action: ActionDeleted}); err != nil {
+		t.Fatalf("append after failure: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer a.Close()
+	events := a.History(1)
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2", events)
+	}
+	if events[1].Action != ActionDeleted || events[1].Revision != 2 {
+		t.Errorf("second event = %+v, want deleted with revision 2", events[1])
+	}
+}
@@ -0,0 +1,199 @@
+package task
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var tasksBucket = []byte("tasks")
+
+// BoltRepo хранит задачи во встроенном key-value хранилище bbolt:
+// ключ — id в big-endian (так курсор обходит задачи по порядку), значение — JSON задачи.
+type BoltRepo struct {
+	db *bolt.DB
+}
+
+func NewBoltRepo(path string) (*BoltRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("open bolt: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(tasksBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open bolt: %w", err)
+	}
+	return &BoltRepo{db: db}, nil
+}
+
+func (r *BoltRepo) List() ([]*Task, error) {
+	out := []*Task{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tasksBucket).ForEach(func(_, v []byte) error {
+			var t Task
+			if err := json.Unmarshal(v, &t); err != nil {
+				return err
+			}
+			out = append(out, &t)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	return out, nil
+}
+
+func (r *BoltRepo) Get(id int64) (*Task, error) {
+	var t *Task
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		t, err = getBolt(tx.Bucket(tasksBucket), id)
+		return err
+	})
+	return t, err
+}
+
+func (r *BoltRepo) Create(title string) (*Task, error) {
+	var t *Task
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		t = &Task{ID: int64(seq), Title: title, CreatedAt: now, UpdatedAt: now}
+		return putBolt(b, t)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	return t, nil
+}
+
+func (r *BoltRepo) Update(id int64, title string, done bool) (*Task, error) {
+	var t *Task
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+		var err error
+		if t, err = getBolt(b, id); err != nil {
+			return err
+		}
+		t.Title = title
+		t.Done = done
+		t.UpdatedAt = time.Now()
+		return putBolt(b, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *BoltRepo) Delete(id int64) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+		if b.Get(boltKey(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete(boltKey(id))
+	})
+}
+
+func (r *BoltRepo) Import(tasks []*Task) error {
+	ids := make(map[*Task]int64)
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+		for _, t := range withIDsFirst(tasks) {
+			if t.ID == 0 {
+				seq, err := b.NextSequence()
+				if err != nil {
+					return err
+				}
+				ids[t] = int64(seq)
+			}
+			c := *t
+			if id, ok := ids[t]; ok {
+				c.ID = id
+			}
+			if err := putBolt(b, &c); err != nil {
+				return err
+			}
+			if uint64(t.ID) > b.Sequence() {
+				if err := b.SetSequence(uint64(t.ID)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("import tasks: %w", err)
+	}
+	for t, id := range ids {
+		t.ID = id
+	}
+	return nil
+}
+
+func (r *BoltRepo) LastID() (int64, error) {
+	var id int64
+	err := r.db.View(func(tx *bolt.Tx) error {
+		id = int64(tx.Bucket(tasksBucket).Sequence())
+		return nil
+	})
+	return id, err
+}
+
+func (r *BoltRepo) SetLastID(id int64) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(tasksBucket)
+		if uint64(id) <= b.Sequence() {
+			return nil
+		}
+		return b.SetSequence(uint64(id))
+	})
+}
+
+func (r *BoltRepo) Close() error {
+	return r.db.Close()
+}
+
+func getBolt(b *bolt.Bucket, id int64) (*Task, error) {
+	v := b.Get(boltKey(id))
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	var t Task
+	if err := json.Unmarshal(v, &t); err != nil {
+		return nil, fmt.Errorf("get task %d: %w", id, err)
+	}
+	return &t, nil
+}
+
+func putBolt(b *bolt.Bucket, t *Task) error {
+	v, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return b.Put(boltKey(t.ID), v)
+}
+
+func boltKey(id int64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(id))
+	return k
+}
@@ -1,19 +1,31 @@
 package task
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/samber/lo"
+	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"sync"
 )
 
 type Handler struct {
-	repo *Repo
+	repo  Repository
+	audit *AuditLog
+	// mu сериализует изменения, чтобы в журнал попадали точные старые значения
+	mu sync.Mutex
 }
 
-func NewHandler(repo *Repo) *Handler {
-	return &Handler{repo: repo}
+// NewHandler создаёт обработчики; при audit == nil журнал ведётся только в памяти.
+func NewHandler(repo Repository, audit *AuditLog) *Handler {
+	if audit == nil {
+		audit, _ = OpenAuditLog("")
+	}
+	return &Handler{repo: repo, audit: audit}
 }
 
 func (h *Handler) RoutesV1() chi.Router {
@@ -28,19 +40,33 @@ func (h *Handler) RoutesV1() chi.Router {
 
 func (h *Handler) RoutesV2() chi.Router {
 	r := chi.NewRouter()
-	r.Get("/", h.listWithFilter) // GET /tasks
-	r.Post("/", h.create)        // POST /tasks
-	r.Get("/{id}", h.get)        // GET /tasks/{id}
-	r.Put("/{id}", h.update)     // PUT /tasks/{id}
-	r.Delete("/{id}", h.delete)  // DELETE /tasks/{id}
+	r.Get("/", h.listWithFilter)       // GET /tasks
+	r.Get("/export", h.export)         // GET /tasks/export?format=csv|json|ndjson
+	r.Post("/import", h.importTasks)   // POST /tasks/import
+	r.Post("/", h.create)              // POST /tasks
+	r.Get("/{id}", h.get)              // GET /tasks/{id}
+	r.Put("/{id}", h.update)           // PUT /tasks/{id}
+	r.Delete("/{id}", h.delete)        // DELETE /tasks/{id}
+	r.Get("/{id}/history", h.history)  // GET /tasks/{id}/history
+	r.Post("/{id}/restore", h.restore) // POST /tasks/{id}/restore
 	return r
 }
 
+// Размер страницы v1: без limit — 7, при limit < 1 — 10, как было до v2.
+const (
+	v1DefaultLimit  = 7
+	v1FallbackLimit = 10
+)
+
+// list — список v1: голый массив, упорядоченный по id. Фильтр done и
+// пагинация (page, limit) необязательны, некорректные значения игнорируются.
 func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
-	all := h.repo.List()
+	all, err := h.repo.List()
+	if err != nil {
+		repoError(w, err)
+		return
+	}
 	params := r.URL.Query()
-	//pageV, isPage := params["q"]
-	//limitV, isLimit := params["limit"]
 	doneStr := params.Get("done")
 	done, err := strconv.ParseBool(doneStr)
 	var result []*Task
@@ -51,47 +77,47 @@ func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
 	} else {
 		result = all
 	}
+	slices.SortFunc(result, func(a, b *Task) int { return cmp.Compare(a.ID, b.ID) })
 
 	pageStr := params.Get("page")
 	page, err := strconv.Atoi(pageStr)
 	if err == nil {
-		limitStr := params.Get("limit")
-		limit, err := strconv.Atoi(limitStr)
+		limit, err := strconv.Atoi(params.Get("limit"))
 		if err != nil {
-			limit = 7
+			limit = v1DefaultLimit
 		}
 		result = paginate(result, page, limit)
 	}
 	writeJSON(w, http.StatusOK, result)
 }
 
+type listResponse struct {
+	Items []*Task `json:"items"`
+	Total int     `json:"total"`
+	Page  int     `json:"page"`
+	Limit int     `json:"limit"`
+}
+
+type paramsErrorResponse struct {
+	Error   string       `json:"error"`
+	Details []ParamError `json:"details"`
+}
+
+// listWithFilter — список v2: конверт с общим числом, фильтры, поиск и сортировка.
+// Некорректные параметры — 400 со списком ошибок.
 func (h *Handler) listWithFilter(w http.ResponseWriter, r *http.Request) {
-	all := h.repo.List()
-	params := r.URL.Query()
-	//pageV, isPage := params["q"]
-	//limitV, isLimit := params["limit"]
-	doneStr := params.Get("done")
-	done, err := strconv.ParseBool(doneStr)
-	var result []*Task
-	if err == nil {
-		result = lo.Filter(all, func(item *Task, _ int) bool {
-			return item.Done == done
-		})
-	} else {
-		result = all
+	q, errs := parseListQuery(r.URL.Query())
+	if len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, paramsErrorResponse{Error: "invalid query parameters", Details: errs})
+		return
 	}
-
-	pageStr := params.Get("page")
-	page, err := strconv.Atoi(pageStr)
-	if err == nil {
-		limitStr := params.Get("limit")
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil {
-			limit = 7
-		}
-		result = paginate(result, page, limit)
+	all, err := h.repo.List()
+	if err != nil {
+		repoError(w, err)
+		return
 	}
-	writeJSON(w, http.StatusOK, result)
+	items, total := q.Apply(all)
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Page: q.Page, Limit: q.Limit})
 }
 
 func paginate(items []*Task, page, pageSize int) []*Task {
@@ -99,7 +125,7 @@ func paginate(items []*Task, page, pageSize int) []*Task {
 		page = 1
 	}
 	if pageSize < 1 {
-		pageSize = 10 // значение по умолчанию
+		pageSize = v1FallbackLimit
 	}
 
 	start := (page - 1) * pageSize
@@ -122,7 +148,7 @@ func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
 	}
 	t, err := h.repo.Get(id)
 	if err != nil {
-		httpError(w, http.StatusNotFound, err.Error())
+		repoError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, t)
@@ -138,11 +164,18 @@ func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "invalid json: require non-empty title")
 		return
 	}
-	if len(req.Title) < 3 || len(req.Title) > 100 {
+	if !validTitle(req.Title) {
 		httpError(w, http.StatusUnprocessableEntity, "invalid title")
 		return
 	}
-	t := h.repo.Create(req.Title)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, err := h.repo.Create(req.Title)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	h.record(r, ActionCreated, t.ID, nil, t)
 	writeJSON(w, http.StatusCreated, t)
 }
 
@@ -161,11 +194,19 @@ func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
 		httpError(w, http.StatusBadRequest, "invalid json: require non-empty title")
 		return
 	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	old, err := h.repo.Get(id)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
 	t, err := h.repo.Update(id, req.Title, req.Done)
 	if err != nil {
-		httpError(w, http.StatusNotFound, err.Error())
+		repoError(w, err)
 		return
 	}
+	h.record(r, ActionUpdated, id, old, t)
 	writeJSON(w, http.StatusOK, t)
 }
 
@@ -174,10 +215,18 @@ func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
 	if bad {
 		return
 	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	old, err := h.repo.Get(id)
+	if err != nil {
+		repoError(w, err)
+		return
+	}
 	if err := h.repo.Delete(id); err != nil {
-		httpError(w, http.StatusNotFound, err.Error())
+		repoError(w, err)
 		return
 	}
+	h.record(r, ActionDeleted, id, old, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -202,3 +251,13 @@ func writeJSON(w http.ResponseWriter, code int, v any) {
 func httpError(w http.ResponseWriter, code int, msg string) {
 	writeJSON(w, code, map[string]string{"error": msg})
 }
+
+// repoError отвечает 404 для ненайденной задачи и 500 для сбоев хранилища.
+func repoError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		httpError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	log.Printf("task repo: %v", err)
+	httpError(w, http.StatusInternalServerError, "storage error")
+}
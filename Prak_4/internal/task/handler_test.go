@@ -0,0 +1,48 @@
+package task
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// brokenRepo — хранилище, у которого отказал диск или драйвер.
+type brokenRepo struct {
+	Repository
+	err error
+}
+
+func (r brokenRepo) Get(int64) (*Task, error) { return nil, r.err }
+
+func TestGetMapsRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     int
+		body     string
+		leakText string
+	}{
+		{"not found", ErrNotFound, http.StatusNotFound, ErrNotFound.Error(), ""},
+		{"storage failure", errors.New("sqlite: database disk image is malformed"), http.StatusInternalServerError, "storage error", "malformed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(brokenRepo{err: tt.err}, nil)
+			rec := httptest.NewRecorder()
+			h.RoutesV2().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/7", nil))
+
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			body := rec.Body.String()
+			if !strings.Contains(body, tt.body) {
+				t.Errorf("body = %s, want %q", body, tt.body)
+			}
+			if tt.leakText != "" && strings.Contains(body, tt.leakText) {
+				t.Errorf("body leaks driver error: %s", body)
+			}
+		})
+	}
+}
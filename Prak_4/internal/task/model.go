@@ -4,6 +4,11 @@ import (
 	"time"
 )
 
+const (
+	titleMinLen = 3
+	titleMaxLen = 100
+)
+
 type Task struct {
 	ID        int64     `json:"id"`
 	Title     string    `json:"title"`
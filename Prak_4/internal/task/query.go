@@ -0,0 +1,185 @@
+package task
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// Поля сортировки списка v2.
+const (
+	SortID      = "id"
+	SortCreated = "created"
+	SortUpdated = "updated"
+	SortTitle   = "title"
+)
+
+// ListQuery — фильтры, сортировка и страница списка задач.
+// Нижние границы дат включительные, верхние — тоже, кроме дат без времени:
+// created_to=2025-10-11 означает «до конца 11 октября».
+type ListQuery struct {
+	Done        *bool
+	Search      string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Sort        string
+	Desc        bool
+	Page        int
+	Limit       int
+}
+
+// ParamError — ошибка в одном параметре запроса.
+type ParamError struct {
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+// parseListQuery разбирает параметры v2 и собирает все ошибки сразу,
+// чтобы клиент исправил их за один заход.
+func parseListQuery(params url.Values) (ListQuery, []ParamError) {
+	q := ListQuery{Sort: SortID, Page: 1, Limit: defaultPageLimit}
+	var errs []ParamError
+	fail := func(param, format string, args ...any) {
+		errs = append(errs, ParamError{Param: param, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s := params.Get("page"); s != "" {
+		page, err := strconv.Atoi(s)
+		if err != nil || page < 1 {
+			fail("page", "must be a positive integer")
+		} else {
+			q.Page = page
+		}
+	}
+	if s := params.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			fail("limit", "must be an integer between 1 and %d", maxPageLimit)
+		} else {
+			q.Limit = limit
+		}
+	}
+	if s := params.Get("done"); s != "" {
+		done, err := strconv.ParseBool(s)
+		if err != nil {
+			fail("done", "must be true or false")
+		} else {
+			q.Done = &done
+		}
+	}
+	q.Search = strings.TrimSpace(params.Get("q"))
+
+	if s := params.Get("sort"); s != "" {
+		field := strings.TrimPrefix(s, "-")
+		switch field {
+		case SortID, SortCreated, SortUpdated, SortTitle:
+			q.Sort, q.Desc = field, strings.HasPrefix(s, "-")
+		default:
+			fail("sort", "must be one of id, created, updated, title, optionally prefixed with -")
+		}
+	}
+	// order, если задан, важнее префикса "-" в sort
+	switch order := params.Get("order"); order {
+	case "":
+	case "asc":
+		q.Desc = false
+	case "desc":
+		q.Desc = true
+	default:
+		fail("order", "must be asc or desc")
+	}
+
+	bound := func(param string, upper bool) time.Time {
+		s := params.Get(param)
+		if s == "" {
+			return time.Time{}
+		}
+		t, err := parseBound(s, upper)
+		if err != nil {
+			fail(param, "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		return t
+	}
+	q.CreatedFrom, q.CreatedTo = bound("created_from", false), bound("created_to", true)
+	q.UpdatedFrom, q.UpdatedTo = bound("updated_from", false), bound("updated_to", true)
+	if !q.CreatedFrom.IsZero() && !q.CreatedTo.IsZero() && q.CreatedFrom.After(q.CreatedTo) {
+		fail("created_from", "must not be after created_to")
+	}
+	if !q.UpdatedFrom.IsZero() && !q.UpdatedTo.IsZero() && q.UpdatedFrom.After(q.UpdatedTo) {
+		fail("updated_from", "must not be after updated_to")
+	}
+	return q, errs
+}
+
+// parseBound принимает RFC 3339 или дату; дата как верхняя граница
+// превращается в последний момент этого дня (UTC).
+func parseBound(s string, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if upper {
+		d = d.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return d, nil
+}
+
+// Apply фильтрует и сортирует задачи и возвращает запрошенную страницу
+// вместе с общим числом подходящих задач.
+func (q ListQuery) Apply(tasks []*Task) ([]*Task, int) {
+	search := strings.ToLower(q.Search)
+	matched := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		if q.matches(t, search) {
+			matched = append(matched, t)
+		}
+	}
+	slices.SortFunc(matched, q.compare)
+	return paginate(matched, q.Page, q.Limit), len(matched)
+}
+
+func (q ListQuery) matches(t *Task, search string) bool {
+	if q.Done != nil && t.Done != *q.Done {
+		return false
+	}
+	if search != "" && !strings.Contains(strings.ToLower(t.Title), search) {
+		return false
+	}
+	return inRange(t.CreatedAt, q.CreatedFrom, q.CreatedTo) && inRange(t.UpdatedAt, q.UpdatedFrom, q.UpdatedTo)
+}
+
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
+
+// compare упорядочивает по выбранному полю, при равенстве — по id.
+func (q ListQuery) compare(a, b *Task) int {
+	var c int
+	switch q.Sort {
+	case SortCreated:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case SortUpdated:
+		c = a.UpdatedAt.Compare(b.UpdatedAt)
+	case SortTitle:
+		c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	}
+	c = cmp.Or(c, cmp.Compare(a.ID, b.ID))
+	if q.Desc {
+		return -c
+	}
+	return c
+}
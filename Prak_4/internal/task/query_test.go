@@ -0,0 +1,174 @@
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseListQuery(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	yes := true
+	tests := []struct {
+		query string
+		want  ListQuery
+	}{
+		{"", ListQuery{Sort: SortID, Page: 1, Limit: defaultPageLimit}},
+		{"page=3&limit=100", ListQuery{Sort: SortID, Page: 3, Limit: 100}},
+		{"done=true&q=%20milk%20", ListQuery{Done: &yes, Search: "milk", Sort: SortID, Page: 1, Limit: defaultPageLimit}},
+		{"sort=-created", ListQuery{Sort: SortCreated, Desc: true, Page: 1, Limit: defaultPageLimit}},
+		{"sort=title&order=desc", ListQuery{Sort: SortTitle, Desc: true, Page: 1, Limit: defaultPageLimit}},
+		{"sort=-title&order=asc", ListQuery{Sort: SortTitle, Page: 1, Limit: defaultPageLimit}},
+		{"created_from=2025-10-01&created_to=2025-10-11", ListQuery{
+			CreatedFrom: day("2025-10-01"),
+			CreatedTo:   day("2025-10-12").Add(-time.Nanosecond),
+			Sort:        SortID, Page: 1, Limit: defaultPageLimit,
+		}},
+		{"updated_to=2025-10-11T12:00:00Z", ListQuery{
+			UpdatedTo: time.Date(2025, 10, 11, 12, 0, 0, 0, time.UTC),
+			Sort:      SortID, Page: 1, Limit: defaultPageLimit,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			got, errs := parseListQuery(params)
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseListQueryCollectsAllErrors(t *testing.T) {
+	tests := []struct {
+		query  string
+		params []string
+	}{
+		{"page=0", []string{"page"}},
+		{"limit=101", []string{"limit"}},
+		{"limit=x&page=-1", []string{"page", "limit"}},
+		{"done=maybe", []string{"done"}},
+		{"sort=priority&order=up", []string{"sort", "order"}},
+		{"created_from=yesterday", []string{"created_from"}},
+		{"updated_from=2025-10-12&updated_to=2025-10-11", []string{"updated_from"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			_, errs := parseListQuery(params)
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Param)
+			}
+			if !slices.Equal(got, tt.params) {
+				t.Errorf("errors for %v, want %v", got, tt.params)
+			}
+		})
+	}
+}
+
+func sampleTasks() []*Task {
+	base := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	var out []*Task
+	for i, title := range []string{"buy milk", "Call mom", "buy bread", "write report", "Milk the cow"} {
+		at := base.AddDate(0, 0, i)
+		out = append(out, &Task{ID: int64(i + 1), Title: title, Done: i%2 == 1, CreatedAt: at, UpdatedAt: at})
+	}
+	// перемешиваем, как это делает обход map
+	slices.Reverse(out)
+	return out
+}
+
+func ids(tasks []*Task) []int64 {
+	out := make([]int64, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, t.ID)
+	}
+	return out
+}
+
+func TestListQueryApply(t *testing.T) {
+	tests := []struct {
+		query string
+		ids   []int64
+		total int
+	}{
+		{"", []int64{1, 2, 3, 4, 5}, 5},
+		{"done=true", []int64{2, 4}, 2},
+		{"q=MILK", []int64{1, 5}, 2},
+		{"sort=title", []int64{3, 1, 2, 5, 4}, 5},
+		{"sort=-created&limit=2", []int64{5, 4}, 5},
+		{"limit=2&page=3", []int64{5}, 5},
+		{"limit=2&page=4", []int64{}, 5},
+		{"created_from=2025-10-02&created_to=2025-10-03", []int64{2, 3}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			params, _ := url.ParseQuery(tt.query)
+			q, errs := parseListQuery(params)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			items, total := q.Apply(sampleTasks())
+			if got := ids(items); !slices.Equal(got, tt.ids) || total != tt.total {
+				t.Errorf("got %v (total %d), want %v (total %d)", got, total, tt.ids, tt.total)
+			}
+		})
+	}
+}
+
+// listRepo отдаёт фиксированный список задач.
+type listRepo struct {
+	Repository
+	tasks []*Task
+}
+
+func (r listRepo) List() ([]*Task, error) { return r.tasks, nil }
+
+func TestListV1(t *testing.T) {
+	var tasks []*Task
+	for i := 20; i >= 1; i-- {
+		tasks = append(tasks, &Task{ID: int64(i), Title: fmt.Sprintf("task %d", i), Done: i%2 == 0})
+	}
+	tests := []struct {
+		query string
+		ids   []int64
+	}{
+		{"", []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}},
+		{"page=1", []int64{1, 2, 3, 4, 5, 6, 7}},
+		{"page=2&limit=x", []int64{8, 9, 10, 11, 12, 13, 14}},
+		{"page=1&limit=0", []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"page=2&limit=3&done=true", []int64{8, 10, 12}},
+		{"done=maybe&page=9", []int64{}},
+	}
+	h := NewHandler(listRepo{tasks: tasks}, nil)
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.RoutesV1().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d", rec.Code)
+			}
+			var got []*Task
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("v1 must return a bare array: %v", err)
+			}
+			if !slices.Equal(ids(got), tt.ids) {
+				t.Errorf("ids = %v, want %v", ids(got), tt.ids)
+			}
+		})
+	}
+}
@@ -4,35 +4,59 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
-const filePath = "sources/tasks.json"
+// DefaultFilePath — файл с задачами, если путь не задан в конфигурации.
+const DefaultFilePath = "sources/tasks.json"
 
 var ErrNotFound = errors.New("task not found")
 
+// Repo хранит задачи в памяти и после каждого изменения сохраняет их в JSON-файл.
+// Рядом с файлом лежит резервная копия предыдущей версии (<path>.bak).
 type Repo struct {
 	mu    sync.RWMutex
-	seq   int64
+	path  string
+	seq   int64 // последний выданный id, сохраняется в файл
 	items map[int64]*Task
 }
 
-func NewRepo() *Repo {
-	r := &Repo{items: make(map[int64]*Task)}
-	_ = r.LoadFromFile()
-	return r
+// fileData — содержимое файла. Счётчик id хранится вместе с задачами, чтобы
+// после перезапуска id удалённых задач не выдавались повторно (как AUTOINCREMENT
+// в sqlite и NextSequence в bolt): журнал аудита различает задачи по id.
+type fileData struct {
+	Seq   int64           `json:"seq"`
+	Tasks map[int64]*Task `json:"tasks"`
 }
 
-func (r *Repo) List() []*Task {
+// NewRepo загружает задачи из path. Если файл повреждён или пропал,
+// данные поднимаются из резервной копии.
+func NewRepo(path string) (*Repo, error) {
+	if path == "" {
+		path = DefaultFilePath
+	}
+	r := &Repo{path: path, items: make(map[int64]*Task)}
+	if err := r.LoadFromFile(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// List и Get возвращают копии, чтобы последующие изменения не гонялись
+// с сериализацией ответа.
+func (r *Repo) List() ([]*Task, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	out := make([]*Task, 0, len(r.items))
 	for _, t := range r.items {
-		out = append(out, t)
+		c := *t
+		out = append(out, &c)
 	}
-	return out
+	return out, nil
 }
 
 func (r *Repo) Get(id int64) (*Task, error) {
@@ -42,88 +66,262 @@ func (r *Repo) Get(id int64) (*Task, error) {
 	if !ok {
 		return nil, ErrNotFound
 	}
-	return t, nil
+	c := *t
+	return &c, nil
 }
 
-func (r *Repo) Create(title string) *Task {
+func (r *Repo) Create(title string) (*Task, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.seq++
 	now := time.Now()
-	t := &Task{ID: r.seq, Title: title, CreatedAt: now, UpdatedAt: now, Done: false}
+	t := &Task{ID: r.seq + 1, Title: title, CreatedAt: now, UpdatedAt: now, Done: false}
 	r.items[t.ID] = t
-	_ = r.SaveToFile() // сохраняем
-	return t
+	r.seq = t.ID
+	if err := r.saveLocked(); err != nil {
+		delete(r.items, t.ID)
+		r.seq--
+		return nil, err
+	}
+	c := *t
+	return &c, nil
 }
 
 func (r *Repo) Update(id int64, title string, done bool) (*Task, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	t, ok := r.items[id]
+	old, ok := r.items[id]
 	if !ok {
 		return nil, ErrNotFound
 	}
+	t := *old
 	t.Title = title
 	t.Done = done
 	t.UpdatedAt = time.Now()
-	_ = r.SaveToFile()
-	return t, nil
+	r.items[id] = &t
+	if err := r.saveLocked(); err != nil {
+		r.items[id] = old
+		return nil, err
+	}
+	c := t
+	return &c, nil
+}
+
+func (r *Repo) LastID() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.seq, nil
+}
+
+func (r *Repo) SetLastID(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id <= r.seq {
+		return nil
+	}
+	old := r.seq
+	r.seq = id
+	if err := r.saveLocked(); err != nil {
+		r.seq = old
+		return err
+	}
+	return nil
 }
 
 func (r *Repo) Delete(id int64) error {
-	//r.mu.Lock()
-	//defer r.mu.Unlock()
-	if _, ok := r.items[id]; !ok {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.items[id]
+	if !ok {
 		return ErrNotFound
 	}
 	delete(r.items, id)
-	_ = r.SaveToFile()
+	if err := r.saveLocked(); err != nil {
+		r.items[id] = t
+		return err
+	}
 	return nil
 }
 
-func (r *Repo) LoadFromFile() error {
+func (r *Repo) Import(tasks []*Task) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	file, err := os.Open(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			r.items = map[int64]*Task{}
-			r.seq = 0
-			return nil
+	oldSeq := r.seq
+	seq := r.seq
+	for _, t := range tasks {
+		seq = max(seq, t.ID)
+	}
+	var assigned []*Task
+	prev := make(map[int64]*Task, len(tasks))
+	for _, t := range withIDsFirst(tasks) {
+		if t.ID == 0 {
+			seq++
+			t.ID = seq
+			assigned = append(assigned, t)
+		}
+		if _, seen := prev[t.ID]; !seen {
+			prev[t.ID] = r.items[t.ID]
+		}
+		c := *t
+		r.items[t.ID] = &c
+	}
+	r.seq = seq
+	if err := r.saveLocked(); err != nil {
+		r.seq = oldSeq
+		for id, old := range prev {
+			if old == nil {
+				delete(r.items, id)
+			} else {
+				r.items[id] = old
+			}
+		}
+		for _, t := range assigned {
+			t.ID = 0
 		}
-		fmt.Println("Error creating file:", err)
 		return err
 	}
-	defer file.Close()
+	return nil
+}
 
-	if err := json.NewDecoder(file).Decode(&r.items); err != nil {
-		return err
+// Close ничего не делает: файл и так сохраняется после каждого изменения.
+func (r *Repo) Close() error {
+	return nil
+}
+
+// LoadFromFile перечитывает задачи с диска. Отсутствие обоих файлов — это
+// пустое хранилище, а повреждённые основной файл и копия — ошибка: начинать
+// с пустого списка и затирать данные при первой записи нельзя.
+func (r *Repo) LoadFromFile() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items, seq, err := readTasks(r.path)
+	recovered := false
+	if err != nil {
+		bak := backupPath(r.path)
+		var bakErr error
+		items, seq, bakErr = readTasks(bak)
+		switch {
+		case bakErr == nil:
+			log.Printf("tasks file %s is unreadable (%v), recovered %d tasks from %s", r.path, err, len(items), bak)
+			recovered = true
+			// повреждённый файл откладываем в сторону: иначе запись ниже
+			// переименует его в .bak поверх единственной целой копии
+			if !errors.Is(err, os.ErrNotExist) {
+				if err := os.Rename(r.path, corruptPath(r.path)); err != nil {
+					return fmt.Errorf("load tasks: %w", err)
+				}
+			}
+		case errors.Is(err, os.ErrNotExist) && errors.Is(bakErr, os.ErrNotExist):
+			items = map[int64]*Task{}
+		default:
+			return fmt.Errorf("load tasks: %w (backup: %v)", err, bakErr)
+		}
 	}
 
-	// вычисляем nextID
-	var maxID int64
-	for _, t := range r.items {
-		if t.ID > maxID {
-			maxID = t.ID
+	r.items = items
+	r.seq = seq
+	if recovered {
+		// возвращаем основной файл в рабочее состояние
+		if err := r.saveLocked(); err != nil {
+			return err
 		}
 	}
-	r.seq = maxID
 	return nil
 }
 
 func (r *Repo) SaveToFile() error {
-	//r.mu.Lock()
-	//defer r.mu.Unlock()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.saveLocked()
+}
 
-	file, err := os.Create(filePath)
+// saveLocked пишет задачи во временный файл, сбрасывает его на диск и только
+// потом подменяет основной файл. Прежняя версия остаётся в <path>.bak, так что
+// падение в любой момент оставляет на диске хотя бы один целый файл.
+func (r *Repo) saveLocked() error {
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("save tasks: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.path)+".tmp-*")
 	if err != nil {
-		fmt.Println("Error creating file:", err)
-		return err
+		return fmt.Errorf("save tasks: %w", err)
 	}
-	defer file.Close()
+	defer os.Remove(tmp.Name()) // после успешного rename файла уже нет
 
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(tmp)
 	encoder.SetIndent("", "  ") // красиво форматировать
-	return encoder.Encode(r.items)
+	if err := encoder.Encode(fileData{Seq: r.seq, Tasks: r.items}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save tasks: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save tasks: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("save tasks: %w", err)
+	}
+
+	if err := os.Rename(r.path, backupPath(r.path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("save tasks: backup: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("save tasks: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// readTasks читает задачи и счётчик id. Файлы старого формата — просто
+// объект id -> задача — тоже читаются, счётчиком для них служит максимальный id.
+func readTasks(path string) (map[int64]*Task, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	file := fileData{Tasks: map[int64]*Task{}}
+	if _, ok := probe["tasks"]; ok {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = json.Unmarshal(data, &file.Tasks)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", path, err)
+	}
+	if file.Tasks == nil {
+		file.Tasks = map[int64]*Task{}
+	}
+	for id, t := range file.Tasks {
+		if t == nil || t.ID != id {
+			return nil, 0, fmt.Errorf("%s: task %d is malformed", path, id)
+		}
+		file.Seq = max(file.Seq, id)
+	}
+	return file.Tasks, file.Seq, nil
+}
+
+func backupPath(path string) string {
+	return path + ".bak"
+}
+
+func corruptPath(path string) string {
+	return path + ".corrupt"
+}
+
+// syncDir фиксирует на диске сами переименования.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", dir, err)
+	}
+	return nil
 }
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestRepo(t *testing.T) (*Repo, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	r, err := NewRepo(path)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	return r, path
+}
+
+func mustCreate(t *testing.T, r Repository, title string) *Task {
+	t.Helper()
+	task, err := r.Create(title)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return task
+}
+
+func TestRepoSaveKeepsPreviousVersion(t *testing.T) {
+	r, path := newTestRepo(t)
+	mustCreate(t, r, "first")
+	mustCreate(t, r, "second")
+
+	cur, _, err := readTasks(path)
+	if err != nil {
+		t.Fatalf("read main file: %v", err)
+	}
+	if len(cur) != 2 {
+		t.Fatalf("main file has %d tasks, want 2", len(cur))
+	}
+	prev, _, err := readTasks(backupPath(path))
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if len(prev) != 1 || prev[1].Title != "first" {
+		t.Fatalf("backup = %v, want only the first task", prev)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file %s left after save", e.Name())
+		}
+	}
+}
+
+func TestRepoRecoversFromBackup(t *testing.T) {
+	r, path := newTestRepo(t)
+	mustCreate(t, r, "first")
+	mustCreate(t, r, "second")
+
+	corrupt := []byte(`{"1": {"id": 1, "title": "fir`)
+	if err := os.WriteFile(path, corrupt, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRepo(path)
+	if err != nil {
+		t.Fatalf("NewRepo after corruption: %v", err)
+	}
+	list, _ := r.List()
+	if len(list) != 1 || list[0].Title != "first" {
+		t.Fatalf("recovered %v, want the backup state with one task", list)
+	}
+
+	if _, _, err := readTasks(path); err != nil {
+		t.Errorf("main file is still unreadable after recovery: %v", err)
+	}
+	if bak, _, err := readTasks(backupPath(path)); err != nil || len(bak) != 1 {
+		t.Errorf("backup was damaged by recovery: %v, %v", bak, err)
+	}
+	if got, err := os.ReadFile(corruptPath(path)); err != nil || string(got) != string(corrupt) {
+		t.Errorf("corrupt file not preserved: %q, %v", got, err)
+	}
+
+	// следующая запись работает как обычно и не теряет восстановленные данные
+	mustCreate(t, r, "third")
+	if bak, _, err := readTasks(backupPath(path)); err != nil || len(bak) != 1 {
+		t.Errorf("backup after next save = %v, %v; want the recovered state", bak, err)
+	}
+}
+
+func TestRepoRecoversMissingMainFile(t *testing.T) {
+	r, path := newTestRepo(t)
+	mustCreate(t, r, "first")
+	mustCreate(t, r, "second")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRepo(path)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	if list, _ := r.List(); len(list) != 1 {
+		t.Fatalf("recovered %d tasks, want 1", len(list))
+	}
+	if _, err := os.Stat(corruptPath(path)); !os.IsNotExist(err) {
+		t.Errorf("unexpected %s: %v", corruptPath(path), err)
+	}
+}
+
+func TestRepoRefusesToStartWhenBothFilesAreCorrupt(t *testing.T) {
+	_, path := newTestRepo(t)
+	for _, p := range []string{path, backupPath(path)} {
+		if err := os.WriteFile(p, []byte("not json"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := NewRepo(path); err == nil {
+		t.Fatal("NewRepo succeeded with both files corrupt")
+	}
+	if got, _ := os.ReadFile(path); string(got) != "not json" {
+		t.Errorf("main file was overwritten: %q", got)
+	}
+}
+
+func TestRepoReadsLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	legacy := `{"3": {"id": 3, "title": "old", "done": false}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRepo(path)
+	if err != nil {
+		t.Fatalf("NewRepo: %v", err)
+	}
+	if got, err := r.Get(3); err != nil || got.Title != "old" {
+		t.Fatalf("Get(3) = %+v, %v", got, err)
+	}
+	if next := mustCreate(t, r, "new"); next.ID != 4 {
+		t.Errorf("next id = %d, want 4", next.ID)
+	}
+	if _, seq, err := readTasks(path); err != nil || seq != 4 {
+		t.Errorf("saved seq = %d, %v; want 4 in the new format", seq, err)
+	}
+}
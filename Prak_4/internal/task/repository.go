@@ -0,0 +1,102 @@
+package task
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Repository — хранилище задач, от которого зависит Handler.
+type Repository interface {
+	List() ([]*Task, error)
+	Get(id int64) (*Task, error)
+	Create(title string) (*Task, error)
+	Update(id int64, title string, done bool) (*Task, error)
+	Delete(id int64) error
+	// Import атомарно сохраняет задачи как есть, с их id и датами, перезаписывая
+	// задачи с теми же id. Задачи с ID == 0 получают новые id, которые
+	// проставляются в переданные структуры. Новые задачи после импорта
+	// получают id больше импортированных.
+	Import(tasks []*Task) error
+	// LastID — наибольший выданный id, включая id уже удалённых задач.
+	LastID() (int64, error)
+	// SetLastID поднимает счётчик: новые задачи получат id больше id.
+	// Значение меньше текущего счётчика ничего не меняет.
+	SetLastID(id int64) error
+	Close() error
+}
+
+// Бэкенды хранилища.
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+	BackendBolt   = "bolt"
+)
+
+var (
+	_ Repository = (*Repo)(nil)
+	_ Repository = (*SQLiteRepo)(nil)
+	_ Repository = (*BoltRepo)(nil)
+)
+
+// DefaultPath — файл по умолчанию для каждого бэкенда.
+func DefaultPath(backend string) string {
+	switch backend {
+	case BackendSQLite:
+		return "sources/tasks.db"
+	case BackendBolt:
+		return "sources/tasks.bolt"
+	}
+	return DefaultFilePath
+}
+
+// Open открывает хранилище выбранного бэкенда; пустой path — файл по умолчанию.
+func Open(backend, path string) (Repository, error) {
+	if backend == "" {
+		backend = BackendJSON
+	}
+	if path == "" {
+		path = DefaultPath(backend)
+	}
+	switch backend {
+	case BackendJSON:
+		return NewRepo(path)
+	case BackendSQLite:
+		return NewSQLiteRepo(path)
+	case BackendBolt:
+		return NewBoltRepo(path)
+	}
+	return nil, fmt.Errorf("unknown tasks backend %q (want json, sqlite or bolt)", backend)
+}
+
+// ParseLocation разбирает строку вида "sqlite:sources/tasks.db" в бэкенд и путь.
+// Без префикса бэкенд угадывается по расширению файла.
+func ParseLocation(loc string) (backend, path string) {
+	if b, p, ok := strings.Cut(loc, ":"); ok && (b == BackendJSON || b == BackendSQLite || b == BackendBolt) {
+		return b, p
+	}
+	switch filepath.Ext(loc) {
+	case ".db", ".sqlite", ".sqlite3":
+		return BackendSQLite, loc
+	case ".bolt":
+		return BackendBolt, loc
+	}
+	return BackendJSON, loc
+}
+
+// withIDsFirst ставит задачи с заданным id перед новыми: новые id выдаются
+// после того, как счётчик учтёт все явные.
+func withIDsFirst(tasks []*Task) []*Task {
+	out := make([]*Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.ID != 0 {
+			out = append(out, t)
+		}
+	}
+	for _, t := range tasks {
+		if t.ID == 0 {
+			out = append(out, t)
+		}
+	}
+	return out
+}
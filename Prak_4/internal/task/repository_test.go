@@ -0,0 +1,227 @@
+package task
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var backends = []string{BackendJSON, BackendSQLite, BackendBolt}
+
+func openTestBackend(t *testing.T, backend, path string) Repository {
+	t.Helper()
+	r, err := Open(backend, path)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", backend, err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			r := openTestBackend(t, backend, filepath.Join(t.TempDir(), "tasks"))
+
+			a := mustCreate(t, r, "first")
+			b := mustCreate(t, r, "second")
+			if a.ID == 0 || b.ID <= a.ID {
+				t.Fatalf("ids = %d, %d; want increasing non-zero ids", a.ID, b.ID)
+			}
+
+			got, err := r.Get(a.ID)
+			if err != nil || got.Title != "first" || got.Done {
+				t.Fatalf("Get = %+v, %v", got, err)
+			}
+
+			upd, err := r.Update(a.ID, "first, renamed", true)
+			if err != nil || upd.Title != "first, renamed" || !upd.Done {
+				t.Fatalf("Update = %+v, %v", upd, err)
+			}
+			if !upd.CreatedAt.Equal(got.CreatedAt) {
+				t.Errorf("Update changed created_at: %v -> %v", got.CreatedAt, upd.CreatedAt)
+			}
+
+			if err := r.Delete(b.ID); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			list, err := r.List()
+			if err != nil || len(list) != 1 || list[0].ID != a.ID {
+				t.Fatalf("List after delete = %v, %v", list, err)
+			}
+		})
+	}
+}
+
+func TestRepositoryNotFound(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			r := openTestBackend(t, backend, filepath.Join(t.TempDir(), "tasks"))
+			if _, err := r.Get(42); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get: %v, want ErrNotFound", err)
+			}
+			if _, err := r.Update(42, "title", false); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Update: %v, want ErrNotFound", err)
+			}
+			if err := r.Delete(42); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Delete: %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestRepositoryImport(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			r := openTestBackend(t, backend, filepath.Join(t.TempDir(), "tasks"))
+			mustCreate(t, r, "existing")
+
+			fresh := &Task{Title: "without id", CreatedAt: created, UpdatedAt: created}
+			err := r.Import([]*Task{
+				fresh,
+				{ID: 1, Title: "overwritten", Done: true, CreatedAt: created, UpdatedAt: created},
+				{ID: 10, Title: "explicit id", CreatedAt: created, UpdatedAt: created},
+			})
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if fresh.ID <= 10 {
+				t.Errorf("task without id got id %d, want > 10", fresh.ID)
+			}
+
+			got, err := r.Get(1)
+			if err != nil || got.Title != "overwritten" || !got.Done || !got.CreatedAt.Equal(created) {
+				t.Errorf("Get(1) = %+v, %v", got, err)
+			}
+			if next := mustCreate(t, r, "after import"); next.ID <= fresh.ID {
+				t.Errorf("new task id %d does not follow imported ids (max %d)", next.ID, fresh.ID)
+			}
+		})
+	}
+}
+
+func TestRepositoryPersistsAcrossReopen(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+			r, err := Open(backend, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := mustCreate(t, r, "kept")
+			if _, err := r.Update(a.ID, "kept", true); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			r = openTestBackend(t, backend, path)
+			got, err := r.Get(a.ID)
+			if err != nil || got.Title != "kept" || !got.Done {
+				t.Fatalf("after reopen Get = %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+// Удалённый id не должен достаться новой задаче и после перезапуска:
+// иначе журнал аудита склеит историю двух разных задач.
+func TestRepositoryDoesNotReuseDeletedIDs(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+			r, err := Open(backend, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mustCreate(t, r, "first")
+			last := mustCreate(t, r, "second")
+			if err := r.Delete(last.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			r = openTestBackend(t, backend, path)
+			if next := mustCreate(t, r, "third"); next.ID <= last.ID {
+				t.Errorf("new task got id %d, deleted id was %d", next.ID, last.ID)
+			}
+		})
+	}
+}
+
+func TestRepositorySetLastID(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks")
+			r, err := Open(backend, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id, err := r.LastID(); err != nil || id != 0 {
+				t.Fatalf("LastID of empty repository = %d, %v", id, err)
+			}
+			if err := r.SetLastID(41); err != nil {
+				t.Fatalf("SetLastID: %v", err)
+			}
+			if err := r.SetLastID(7); err != nil {
+				t.Fatalf("SetLastID lower: %v", err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			r = openTestBackend(t, backend, path)
+			if id, err := r.LastID(); err != nil || id != 41 {
+				t.Errorf("LastID after reopen = %d, %v; want 41", id, err)
+			}
+			if next := mustCreate(t, r, "next"); next.ID != 42 {
+				t.Errorf("new task got id %d, want 42", next.ID)
+			}
+		})
+	}
+}
+
+func TestOpenUnknownBackend(t *testing.T) {
+	if _, err := Open("postgres", filepath.Join(t.TempDir(), "tasks")); err == nil {
+		t.Fatal("Open accepted an unknown backend")
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		loc, backend, path string
+	}{
+		{"sqlite:data/x.json", BackendSQLite, "data/x.json"},
+		{"bolt:x", BackendBolt, "x"},
+		{"json:x.db", BackendJSON, "x.db"},
+		{"sources/tasks.db", BackendSQLite, "sources/tasks.db"},
+		{"tasks.sqlite3", BackendSQLite, "tasks.sqlite3"},
+		{"tasks.bolt", BackendBolt, "tasks.bolt"},
+		{"tasks.json", BackendJSON, "tasks.json"},
+		{`C:\data\tasks.bolt`, BackendBolt, `C:\data\tasks.bolt`},
+	}
+	for _, tt := range tests {
+		backend, path := ParseLocation(tt.loc)
+		if backend != tt.backend || path != tt.path {
+			t.Errorf("ParseLocation(%q) = %q, %q; want %q, %q", tt.loc, backend, path, tt.backend, tt.path)
+		}
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	for backend, want := range map[string]string{
+		BackendJSON:   DefaultFilePath,
+		BackendSQLite: "sources/tasks.db",
+		BackendBolt:   "sources/tasks.bolt",
+		"":            DefaultFilePath,
+	} {
+		if got := DefaultPath(backend); got != want {
+			t.Errorf("DefaultPath(%q) = %q, want %q", backend, got, want)
+		}
+	}
+}
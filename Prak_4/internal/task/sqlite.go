@@ -0,0 +1,221 @@
+package task
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// timeLayout — фиксированная ширина и UTC, чтобы даты в SQLite сортировались как строки.
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	title      TEXT    NOT NULL,
+	done       INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT    NOT NULL,
+	updated_at TEXT    NOT NULL
+)`
+
+// SQLiteRepo хранит задачи во встроенной базе SQLite: каждое изменение —
+// одна строка, а не перезапись всего файла.
+type SQLiteRepo struct {
+	db *sql.DB
+}
+
+func NewSQLiteRepo(path string) (*SQLiteRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	// один писатель: SQLite всё равно сериализует записи
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	return &SQLiteRepo{db: db}, nil
+}
+
+func (r *SQLiteRepo) List() ([]*Task, error) {
+	rows, err := r.db.Query(`SELECT id, title, done, created_at, updated_at FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list tasks: %w", err)
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list tasks: %w", err)
+	}
+	return out, nil
+}
+
+func (r *SQLiteRepo) Get(id int64) (*Task, error) {
+	row := r.db.QueryRow(`SELECT id, title, done, created_at, updated_at FROM tasks WHERE id = ?`, id)
+	t, err := scanTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get task %d: %w", id, err)
+	}
+	return t, nil
+}
+
+func (r *SQLiteRepo) Create(title string) (*Task, error) {
+	now := time.Now()
+	res, err := r.db.Exec(`INSERT INTO tasks (title, done, created_at, updated_at) VALUES (?, 0, ?, ?)`,
+		title, formatTime(now), formatTime(now))
+	if err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+	return &Task{ID: id, Title: title, CreatedAt: now.UTC(), UpdatedAt: now.UTC()}, nil
+}
+
+func (r *SQLiteRepo) Update(id int64, title string, done bool) (*Task, error) {
+	res, err := r.db.Exec(`UPDATE tasks SET title = ?, done = ?, updated_at = ? WHERE id = ?`,
+		title, done, formatTime(time.Now()), id)
+	if err != nil {
+		return nil, fmt.Errorf("update task %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return r.Get(id)
+}
+
+func (r *SQLiteRepo) Delete(id int64) error {
+	res, err := r.db.Exec(`DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete task %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Import пишет задачи одной транзакцией; AUTOINCREMENT сам сдвигает счётчик id,
+// а для задач без id (NULL) выдаёт следующий.
+func (r *SQLiteRepo) Import(tasks []*Task) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("import tasks: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO tasks (id, title, done, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET title = excluded.title, done = excluded.done,
+			created_at = excluded.created_at, updated_at = excluded.updated_at`)
+	if err != nil {
+		return fmt.Errorf("import tasks: %w", err)
+	}
+	defer stmt.Close()
+	ids := make(map[*Task]int64)
+	for _, t := range withIDsFirst(tasks) {
+		var id any
+		if t.ID != 0 {
+			id = t.ID
+		}
+		res, err := stmt.Exec(id, t.Title, t.Done, formatTime(t.CreatedAt), formatTime(t.UpdatedAt))
+		if err != nil {
+			return fmt.Errorf("import task %d: %w", t.ID, err)
+		}
+		if t.ID == 0 {
+			if ids[t], err = res.LastInsertId(); err != nil {
+				return fmt.Errorf("import tasks: %w", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("import tasks: %w", err)
+	}
+	for t, id := range ids {
+		t.ID = id
+	}
+	return nil
+}
+
+// LastID читает счётчик AUTOINCREMENT из sqlite_sequence; строки нет, пока
+// в таблицу ничего не вставляли.
+func (r *SQLiteRepo) LastID() (int64, error) {
+	var id int64
+	err := r.db.QueryRow(`SELECT seq FROM sqlite_sequence WHERE name = 'tasks'`).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read last id: %w", err)
+	}
+	return id, nil
+}
+
+func (r *SQLiteRepo) SetLastID(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("set last id: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE sqlite_sequence SET seq = ? WHERE name = 'tasks' AND seq < ?`, id, id); err != nil {
+		return fmt.Errorf("set last id: %w", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO sqlite_sequence (name, seq)
+		SELECT 'tasks', ? WHERE NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'tasks')`, id); err != nil {
+		return fmt.Errorf("set last id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("set last id: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteRepo) Close() error {
+	return r.db.Close()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (*Task, error) {
+	var (
+		t                Task
+		created, updated string
+	)
+	if err := row.Scan(&t.ID, &t.Title, &t.Done, &created, &updated); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.CreatedAt, err = time.Parse(timeLayout, created); err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = time.Parse(timeLayout, updated); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
@@ -0,0 +1,515 @@
+package task
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+)
+
+// Форматы выгрузки и загрузки.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+var csvHeader = []string{"id", "title", "done", "created_at", "updated_at"}
+
+// export отдаёт задачи потоком, не собирая весь ответ в памяти. Поддерживает
+// те же фильтры и сортировку, что и список v2, но без пагинации.
+func (h *Handler) export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	var contentType string
+	switch format {
+	case FormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case FormatJSON:
+		contentType = "application/json; charset=utf-8"
+	case FormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		httpError(w, http.StatusBadRequest, "format must be one of csv, json, ndjson")
+		return
+	}
+	q, errs := parseListQuery(r.URL.Query())
+	if len(errs) > 0 {
+		writeJSON(w, http.StatusBadRequest, paramsErrorResponse{Error: "invalid query parameters", Details: errs})
+		return
+	}
+	all, err := h.repo.List()
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	search := strings.ToLower(q.Search)
+	tasks := slices.DeleteFunc(all, func(t *Task) bool { return !q.matches(t, search) })
+	slices.SortFunc(tasks, q.compare)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="tasks.`+format+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	var werr error
+	switch format {
+	case FormatCSV:
+		werr = writeCSV(w, tasks)
+	case FormatJSON:
+		werr = writeJSONArray(w, tasks)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, t := range tasks {
+			if werr = enc.Encode(t); werr != nil {
+				break
+			}
+		}
+	}
+	if werr != nil {
+		// заголовки уже ушли, остаётся только оборвать ответ
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func writeCSV(w io.Writer, tasks []*Task) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		rec := []string{
+			strconv.FormatInt(t.ID, 10),
+			t.Title,
+			strconv.FormatBool(t.Done),
+			t.CreatedAt.Format(time.RFC3339Nano),
+			t.UpdatedAt.Format(time.RFC3339Nano),
+		}
+		for i := range rec {
+			rec[i] = escapeCSVCell(rec[i])
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeCSVCell защищает от CSV-инъекции: ячейку, которую табличный редактор
+// принял бы за формулу (=, +, -, @, табуляция в начале), предваряет апострофом.
+// Апостроф добавляется и к ячейке, уже похожей на экранированную, чтобы
+// unescapeCSVCell однозначно восстанавливал исходное значение при импорте.
+func escapeCSVCell(s string) string {
+	if needsCSVEscape(s) {
+		return "'" + s
+	}
+	return s
+}
+
+func unescapeCSVCell(s string) string {
+	if strings.HasPrefix(s, "'") && needsCSVEscape(s[1:]) {
+		return s[1:]
+	}
+	return s
+}
+
+func needsCSVEscape(s string) bool {
+	if s == "" {
+		return false
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t':
+		return true
+	case '\'':
+		return needsCSVEscape(s[1:])
+	}
+	return false
+}
+
+func writeJSONArray(w io.Writer, tasks []*Task) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, t := range tasks {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			b = append([]byte(","), b...)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// Режимы разрешения конфликтов по id при загрузке.
+const (
+	ImportSkip   = "skip"
+	ImportUpsert = "upsert"
+)
+
+// Статусы строк в отчёте о загрузке.
+const (
+	rowCreated = "created"
+	rowUpdated = "updated"
+	rowSkipped = "skipped"
+	rowInvalid = "invalid"
+)
+
+// importRow — одна задача из файла: id и даты необязательны.
+type importRow struct {
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Done      bool       `json:"done"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type rowReport struct {
+	Row    int    `json:"row"`
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type importReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Mode    string      `json:"mode"`
+	Total   int         `json:"total"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Invalid int         `json:"invalid"`
+	Rows    []rowReport `json:"rows"`
+}
+
+// importTasks загружает задачи из csv, json или ndjson. Строки без id создаются
+// заново, строки с существующим id пропускаются (mode=skip) или перезаписываются
+// (mode=upsert). Если хоть одна строка некорректна, не применяется ничего и
+// ответ — 422 с отчётом; dry_run=true только строит отчёт.
+func (h *Handler) importTasks(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	mode := params.Get("mode")
+	if mode == "" {
+		mode = ImportSkip
+	}
+	if mode != ImportSkip && mode != ImportUpsert {
+		httpError(w, http.StatusBadRequest, "mode must be skip or upsert")
+		return
+	}
+	dryRun := false
+	if s := params.Get("dry_run"); s != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			httpError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+	format, err := importFormat(r)
+	if err != nil {
+		httpError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	rows, rowErrs, err := readImport(body, format)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("import is limited to %d bytes", maxImportBytes))
+			return
+		}
+		httpError(w, http.StatusBadRequest, "invalid "+format+": "+err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	all, err := h.repo.List()
+	if err != nil {
+		repoError(w, err)
+		return
+	}
+	existing := make(map[int64]*Task, len(all))
+	for _, t := range all {
+		existing[t.ID] = t
+	}
+
+	rep := importReport{DryRun: dryRun, Mode: mode, Total: len(rows), Rows: make([]rowReport, 0, len(rows))}
+	var toImport []*Task
+	created := map[int]*Task{} // индекс строки отчёта -> новая задача, id станет известен после Import
+	seen := make(map[int64]int)
+	now := time.Now()
+	for i, row := range rows {
+		rr := rowReport{Row: i + 1, ID: row.ID}
+		if msg := rowErrs[i]; msg != "" {
+			rr.Status, rr.Error = rowInvalid, msg
+		} else if msg := row.validate(); msg != "" {
+			rr.Status, rr.Error = rowInvalid, msg
+		} else if first, dup := seen[row.ID]; dup {
+			rr.Status, rr.Error = rowInvalid, fmt.Sprintf("duplicate id, first seen in row %d", first)
+		} else {
+			if row.ID != 0 {
+				seen[row.ID] = i + 1
+			}
+			switch {
+			case row.ID != 0 && existing[row.ID] != nil && mode == ImportSkip:
+				rr.Status = rowSkipped
+			case row.ID != 0 && existing[row.ID] != nil:
+				rr.Status = rowUpdated
+			default:
+				rr.Status = rowCreated
+			}
+			if rr.Status != rowSkipped {
+				t := row.task(now, existing[row.ID])
+				toImport = append(toImport, t)
+				if row.ID == 0 {
+					created[len(rep.Rows)] = t
+				}
+			}
+		}
+		switch rr.Status {
+		case rowCreated:
+			rep.Created++
+		case rowUpdated:
+			rep.Updated++
+		case rowSkipped:
+			rep.Skipped++
+		case rowInvalid:
+			rep.Invalid++
+		}
+		rep.Rows = append(rep.Rows, rr)
+	}
+
+	if rep.Invalid > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, rep)
+		return
+	}
+	if !dryRun && len(toImport) > 0 {
+		if err := h.repo.Import(toImport); err != nil {
+			repoError(w, err)
+			return
+		}
+		for i, t := range created {
+			rep.Rows[i].ID = t.ID
+		}
+		for _, t := range toImport {
+			if old := existing[t.ID]; old != nil {
+				h.record(r, ActionUpdated, t.ID, old, t)
+			} else {
+				h.record(r, ActionCreated, t.ID, nil, t)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, rep)
+}
+
+// importFormat берёт формат из ?format= или из Content-Type.
+func importFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		if f != FormatCSV && f != FormatJSON && f != FormatNDJSON {
+			return "", errors.New("format must be one of csv, json, ndjson")
+		}
+		return f, nil
+	}
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, nil
+	case "application/json", "":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unsupported Content-Type %q, use text/csv, application/json or application/x-ndjson", mt)
+}
+
+// readImport разбирает файл в строки. Ошибки отдельных строк (битый JSON
+// в ndjson, нечисловой id в csv) попадают в rowErrs, ошибка всего файла — в err.
+func readImport(body io.Reader, format string) (rows []importRow, rowErrs []string, err error) {
+	add := func(row importRow, rowErr string) error {
+		if len(rows) == maxImportRows {
+			return fmt.Errorf("at most %d rows per import", maxImportRows)
+		}
+		rows = append(rows, row)
+		rowErrs = append(rowErrs, rowErr)
+		return nil
+	}
+
+	switch format {
+	case FormatCSV:
+		return rows, rowErrs, readCSV(body, add)
+	case FormatNDJSON:
+		// построчно, а не json.Decoder: синтаксическая ошибка в одной строке
+		// не должна обрывать разбор остальных
+		sc := bufio.NewScanner(body)
+		sc.Buffer(make([]byte, 0, 64*1024), maxImportBytes)
+		for line := 1; sc.Scan(); line++ {
+			text := bytes.TrimSpace(sc.Bytes())
+			if line == 1 {
+				text = bytes.TrimPrefix(text, []byte("\ufeff"))
+			}
+			if len(text) == 0 {
+				continue
+			}
+			var row importRow
+			msg := ""
+			if err := json.Unmarshal(text, &row); err != nil {
+				msg = fmt.Sprintf("line %d: %v", line, err)
+			}
+			if err := add(row, msg); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, nil, err
+		}
+		return rows, rowErrs, nil
+	default:
+		dec := json.NewDecoder(body)
+		if tok, err := dec.Token(); err != nil {
+			return nil, nil, err
+		} else if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return nil, nil, errors.New("expected a JSON array of tasks")
+		}
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, nil, err
+			}
+			var row importRow
+			msg := ""
+			if err := json.Unmarshal(raw, &row); err != nil {
+				msg = err.Error()
+			}
+			if err := add(row, msg); err != nil {
+				return nil, nil, err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		return rows, rowErrs, nil
+	}
+}
+
+// readCSV читает CSV с заголовком; обязательна колонка title, остальные
+// (id, done, created_at, updated_at) — по желанию и в любом порядке.
+func readCSV(body io.Reader, add func(importRow, string) error) error {
+	cr := csv.NewReader(body)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return errors.New(`header must contain a "title" column`)
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return unescapeCSVCell(strings.TrimSpace(rec[i]))
+			}
+			return ""
+		}
+
+		var row importRow
+		var problems []string
+		row.Title = field("title")
+		if s := field("id"); s != "" {
+			if row.ID, err = strconv.ParseInt(s, 10, 64); err != nil {
+				problems = append(problems, "id must be an integer")
+			}
+		}
+		if s := field("done"); s != "" {
+			if row.Done, err = strconv.ParseBool(s); err != nil {
+				problems = append(problems, "done must be true or false")
+			}
+		}
+		for _, f := range []struct {
+			name string
+			dst  **time.Time
+		}{{"created_at", &row.CreatedAt}, {"updated_at", &row.UpdatedAt}} {
+			if s := field(f.name); s != "" {
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					problems = append(problems, f.name+" must be an RFC 3339 timestamp")
+					continue
+				}
+				*f.dst = &t
+			}
+		}
+		if err := add(row, strings.Join(problems, "; ")); err != nil {
+			return err
+		}
+	}
+}
+
+func (row importRow) validate() string {
+	if row.ID < 0 {
+		return "id must be positive"
+	}
+	if !validTitle(row.Title) {
+		return fmt.Sprintf("title must be %d-%d characters", titleMinLen, titleMaxLen)
+	}
+	if row.CreatedAt != nil && row.UpdatedAt != nil && row.UpdatedAt.Before(*row.CreatedAt) {
+		return "updated_at must not be before created_at"
+	}
+	return ""
+}
+
+// task превращает строку в задачу. Без created_at дата создания берётся у
+// перезаписываемой задачи prev или равна моменту загрузки, как и updated_at.
+func (row importRow) task(now time.Time, prev *Task) *Task {
+	t := &Task{ID: row.ID, Title: row.Title, Done: row.Done, CreatedAt: now, UpdatedAt: now}
+	if prev != nil {
+		t.CreatedAt = prev.CreatedAt
+	}
+	if row.CreatedAt != nil {
+		t.CreatedAt = *row.CreatedAt
+		t.UpdatedAt = *row.CreatedAt
+	}
+	if row.UpdatedAt != nil {
+		t.UpdatedAt = *row.UpdatedAt
+	}
+	return t
+}
+
+func validTitle(title string) bool {
+	return len(title) >= titleMinLen && len(title) <= titleMaxLen
+}
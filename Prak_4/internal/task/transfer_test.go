@@ -0,0 +1,276 @@
+package task
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func serve(h *Handler, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.RoutesV2().ServeHTTP(rec, req)
+	return rec
+}
+
+func seededHandler(t *testing.T) (*Handler, *Repo) {
+	t.Helper()
+	r, _ := newTestRepo(t)
+	mustCreate(t, r, "buy milk")
+	done := mustCreate(t, r, "call mom")
+	if _, err := r.Update(done.ID, done.Title, true); err != nil {
+		t.Fatal(err)
+	}
+	mustCreate(t, r, "write, \"quoted\" report")
+	return NewHandler(r, nil), r
+}
+
+func decodeReport(t *testing.T, rec *httptest.ResponseRecorder) importReport {
+	t.Helper()
+	var rep importReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil {
+		t.Fatalf("decode report %q: %v", rec.Body.String(), err)
+	}
+	return rep
+}
+
+func TestExportFormats(t *testing.T) {
+	h, _ := seededHandler(t)
+
+	tests := []struct {
+		format      string
+		contentType string
+		titles      func(t *testing.T, body string) []string
+	}{
+		{FormatCSV, "text/csv; charset=utf-8", func(t *testing.T, body string) []string {
+			recs, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(recs[0], csvHeader) {
+				t.Errorf("header = %v", recs[0])
+			}
+			var out []string
+			for _, rec := range recs[1:] {
+				out = append(out, rec[1])
+			}
+			return out
+		}},
+		{FormatJSON, "application/json; charset=utf-8", func(t *testing.T, body string) []string {
+			var tasks []*Task
+			if err := json.Unmarshal([]byte(body), &tasks); err != nil {
+				t.Fatal(err)
+			}
+			var out []string
+			for _, task := range tasks {
+				out = append(out, task.Title)
+			}
+			return out
+		}},
+		{FormatNDJSON, "application/x-ndjson", func(t *testing.T, body string) []string {
+			var out []string
+			sc := bufio.NewScanner(strings.NewReader(body))
+			for sc.Scan() {
+				var task Task
+				if err := json.Unmarshal(sc.Bytes(), &task); err != nil {
+					t.Fatalf("line %q: %v", sc.Text(), err)
+				}
+				out = append(out, task.Title)
+			}
+			return out
+		}},
+	}
+	want := []string{"buy milk", "call mom", `write, "quoted" report`}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			rec := serve(h, http.MethodGet, "/export?format="+tt.format, "", "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if got := tt.titles(t, rec.Body.String()); !slices.Equal(got, want) {
+				t.Errorf("titles = %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		rec := serve(h, http.MethodGet, "/export?format=ndjson&done=true", "", "")
+		if lines := strings.Count(rec.Body.String(), "\n"); lines != 1 {
+			t.Errorf("done=true exported %d tasks, want 1", lines)
+		}
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		if rec := serve(h, http.MethodGet, "/export?format=xml", "", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	src, srcRepo := seededHandler(t)
+	want, _ := srcRepo.List()
+	slices.SortFunc(want, func(a, b *Task) int { return cmp.Compare(a.ID, b.ID) })
+
+	for _, format := range []string{FormatCSV, FormatJSON, FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			exported := serve(src, http.MethodGet, "/export?format="+format, "", "").Body.String()
+
+			dst, _ := newTestRepo(t)
+			rec := serve(NewHandler(dst, nil), http.MethodPost, "/import?format="+format, "", exported)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if rep := decodeReport(t, rec); rep.Created != len(want) || rep.Invalid != 0 {
+				t.Errorf("report = %+v", rep)
+			}
+
+			for _, w := range want {
+				got, err := dst.Get(w.ID)
+				if err != nil {
+					t.Fatalf("task %d not imported: %v", w.ID, err)
+				}
+				if got.Title != w.Title || got.Done != w.Done || !got.CreatedAt.Equal(w.CreatedAt) || !got.UpdatedAt.Equal(w.UpdatedAt) {
+					t.Errorf("task %d = %+v, want %+v", w.ID, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	r, _ := newTestRepo(t)
+	titles := []string{"=HYPERLINK(\"http://x\")", "+1 vote", "-2 days", "@home", "'=already quoted", "it's fine"}
+	for _, title := range titles {
+		mustCreate(t, r, title)
+	}
+	h := NewHandler(r, nil)
+
+	exported := serve(h, http.MethodGet, "/export?format=csv", "", "").Body.String()
+	recs, err := csv.NewReader(strings.NewReader(exported)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cells []string
+	for _, rec := range recs[1:] {
+		cells = append(cells, rec[1])
+	}
+	want := []string{"'=HYPERLINK(\"http://x\")", "'+1 vote", "'-2 days", "'@home", "''=already quoted", "it's fine"}
+	if !slices.Equal(cells, want) {
+		t.Errorf("title cells = %q, want %q", cells, want)
+	}
+
+	dst, _ := newTestRepo(t)
+	if rec := serve(NewHandler(dst, nil), http.MethodPost, "/import?format=csv", "", exported); rec.Code != http.StatusOK {
+		t.Fatalf("import status = %d: %s", rec.Code, rec.Body)
+	}
+	list, _ := dst.List()
+	slices.SortFunc(list, func(a, b *Task) int { return cmp.Compare(a.ID, b.ID) })
+	var got []string
+	for _, task := range list {
+		got = append(got, task.Title)
+	}
+	if !slices.Equal(got, titles) {
+		t.Errorf("imported titles = %q, want %q", got, titles)
+	}
+}
+
+func TestImportNDJSONReportsBrokenLines(t *testing.T) {
+	r, _ := newTestRepo(t)
+	h := NewHandler(r, nil)
+	body := `{"title": "first good task"}
+{"title": "broken
+{"title": "no"}
+
+{"title": "second good task"}
+`
+	rec := serve(h, http.MethodPost, "/import", "application/x-ndjson", body)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body)
+	}
+	rep := decodeReport(t, rec)
+	if rep.Total != 4 || rep.Invalid != 2 || rep.Created != 2 {
+		t.Errorf("report = %+v", rep)
+	}
+	statuses := []string{rowCreated, rowInvalid, rowInvalid, rowCreated}
+	for i, row := range rep.Rows {
+		if row.Status != statuses[i] {
+			t.Errorf("row %d status = %s, want %s", row.Row, row.Status, statuses[i])
+		}
+	}
+	if !strings.HasPrefix(rep.Rows[1].Error, "line 2:") {
+		t.Errorf("broken line error = %q, want the line number", rep.Rows[1].Error)
+	}
+	if list, _ := r.List(); len(list) != 0 {
+		t.Errorf("import with invalid rows applied %d tasks", len(list))
+	}
+}
+
+func TestImportCSVRowErrors(t *testing.T) {
+	r, _ := newTestRepo(t)
+	body := "title,id,done,created_at\n" +
+		"valid title,,true,\n" +
+		"bad id,abc,,\n" +
+		"bad done,,maybe,\n" +
+		"bad date,,,yesterday\n"
+	rec := serve(NewHandler(r, nil), http.MethodPost, "/import", "text/csv", body)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", rec.Code)
+	}
+	rep := decodeReport(t, rec)
+	wantErrs := []string{"", "id must be an integer", "done must be true or false", "created_at must be an RFC 3339 timestamp"}
+	for i, row := range rep.Rows {
+		if row.Error != wantErrs[i] {
+			t.Errorf("row %d error = %q, want %q", row.Row, row.Error, wantErrs[i])
+		}
+	}
+}
+
+func TestImportModes(t *testing.T) {
+	h, r := seededHandler(t)
+	before, _ := r.Get(1)
+	body := `[{"id": 1, "title": "buy oat milk"}, {"title": "a brand new task"}]`
+
+	rep := decodeReport(t, serve(h, http.MethodPost, "/import?mode=skip&dry_run=true", "", body))
+	if rep.Skipped != 1 || rep.Created != 1 || !rep.DryRun {
+		t.Errorf("skip dry run = %+v", rep)
+	}
+	rep = decodeReport(t, serve(h, http.MethodPost, "/import?mode=upsert&dry_run=true", "", body))
+	if rep.Updated != 1 || rep.Created != 1 {
+		t.Errorf("upsert dry run = %+v", rep)
+	}
+	if list, _ := r.List(); len(list) != 3 {
+		t.Fatalf("dry run changed the store: %d tasks", len(list))
+	}
+
+	rec := serve(h, http.MethodPost, "/import?mode=upsert", "", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	rep = decodeReport(t, rec)
+	if rep.Rows[1].ID != 4 {
+		t.Errorf("new task id in report = %d, want 4", rep.Rows[1].ID)
+	}
+	after, _ := r.Get(1)
+	if after.Title != "buy oat milk" || !after.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("upserted task = %+v, want new title and the old created_at %v", after, before.CreatedAt)
+	}
+
+	if rec := serve(h, http.MethodPost, "/import?mode=replace", "", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown mode status = %d, want 400", rec.Code)
+	}
+	if rec := serve(h, http.MethodPost, "/import", "", `{"title": "not an array"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("non-array JSON status = %d, want 400", rec.Code)
+	}
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
@@ -13,7 +20,18 @@ import (
 	myMW "prak4/pkg/middleware"
 )
 
+// shutdownTimeout — сколько ждать завершения активных запросов после сигнала.
+const shutdownTimeout = 10 * time.Second
+
 func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run поднимает сервер и возвращается после SIGINT/SIGTERM, когда активные запросы
+// завершены; отложенные Close хранилища и журнала аудита выполняются в любом случае.
+func run() error {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = ":8080"
@@ -21,10 +39,38 @@ func main() {
 		port = ":" + port
 	}
 
-	repo := task.NewRepo()
-	h := task.NewHandler(repo)
+	repo, err := task.Open(os.Getenv("TASKS_BACKEND"), os.Getenv("TASKS_FILE"))
+	if err != nil {
+		return fmt.Errorf("open tasks: %w", err)
+	}
+	defer repo.Close()
+	auditFile := os.Getenv("TASKS_AUDIT_FILE")
+	if auditFile == "" {
+		auditFile = task.DefaultAuditPath
+	}
+	audit, err := task.OpenAuditLog(auditFile)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer audit.Close()
+	h := task.NewHandler(repo, audit)
+
+	v1, err := loadVersion("v1", "/api/v2/tasks")
+	if err != nil {
+		return err
+	}
+	v2, err := loadVersion("v2", "")
+	if err != nil {
+		return err
+	}
+	versions := myMW.NewVersions(v1, v2)
 
 	r := chi.NewRouter()
+	limit, err := loadRateLimit(r)
+	if err != nil {
+		return err
+	}
+	r.Use(versions.Select("/api", "v2"))
 	r.Use(chimw.RequestID)
 	r.Use(chimw.Recoverer)
 	r.Use(myMW.Logger)
@@ -33,18 +79,118 @@ func main() {
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("OK"))
 	})
+	r.Get("/versions", versions.StatsHandler)
 
 	// v1 без фильтров и без пагинаци
 	r.Route("/api/v1", func(api chi.Router) {
+		api.Use(versions.Handler("v1"))
+		api.Use(limit)
 		api.Mount("/tasks", h.RoutesV1())
 	})
 
 	// v2 с фильтрами и пагинацией
 	r.Route("/api/v2", func(api chi.Router) {
+		api.Use(versions.Handler("v2"))
+		api.Use(limit)
 		api.Mount("/tasks", h.RoutesV2())
 	})
 
-	//addr := ":8080"
-	log.Printf("listening on %s", port)
-	log.Fatal(http.ListenAndServe(port, r))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: port, Handler: r}
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", port)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+	log.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// loadVersion читает политику версии из API_<V>_DEPRECATION, API_<V>_SUNSET
+// и API_<V>_GONE_AFTER: дата RFC 3339 или YYYY-MM-DD (полночь UTC).
+func loadVersion(name, successor string) (myMW.APIVersion, error) {
+	v := myMW.APIVersion{Name: name, Successor: successor}
+	prefix := "API_" + strings.ToUpper(name) + "_"
+	for _, f := range []struct {
+		env string
+		dst *time.Time
+	}{
+		{prefix + "DEPRECATION", &v.Deprecation},
+		{prefix + "SUNSET", &v.Sunset},
+		{prefix + "GONE_AFTER", &v.GoneAfter},
+	} {
+		s := os.Getenv(f.env)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, s); err != nil {
+				return v, fmt.Errorf("%s: want RFC 3339 or YYYY-MM-DD, got %q", f.env, s)
+			}
+		}
+		*f.dst = t
+	}
+	return v, nil
+}
+
+// loadRateLimit собирает ограничитель для /api из RATE_LIMIT_RPS (по умолчанию 10,
+// 0 — без ограничений), RATE_LIMIT_BURST (по умолчанию 20) и RATE_LIMIT_KEY:
+// ip (по умолчанию), apikey (заголовок X-API-Key, известные ключи — в RATE_LIMIT_API_KEYS) или route.
+func loadRateLimit(routes chi.Routes) (func(http.Handler) http.Handler, error) {
+	cfg := myMW.RateLimit{Rate: 10, Burst: 20}
+	if s := os.Getenv("RATE_LIMIT_RPS"); s != "" {
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("RATE_LIMIT_RPS: want a non-negative number, got %q", s)
+		}
+		cfg.Rate = rate
+	}
+	if cfg.Rate == 0 {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+	if s := os.Getenv("RATE_LIMIT_BURST"); s != "" {
+		burst, err := strconv.Atoi(s)
+		if err != nil || burst < 1 {
+			return nil, fmt.Errorf("RATE_LIMIT_BURST: want a positive integer, got %q", s)
+		}
+		cfg.Burst = burst
+	}
+	switch key := os.Getenv("RATE_LIMIT_KEY"); key {
+	case "", "ip":
+		cfg.Key = myMW.KeyByIP
+	case "apikey":
+		var keys []string
+		for _, k := range strings.Split(os.Getenv("RATE_LIMIT_API_KEYS"), ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("RATE_LIMIT_API_KEYS: required when RATE_LIMIT_KEY=apikey")
+		}
+		cfg.Key = myMW.KeyByAPIKey("X-API-Key", keys)
+	case "route":
+		cfg.Key = myMW.KeyByRoute(routes)
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_KEY: want ip, apikey or route, got %q", key)
+	}
+	return myMW.Limit(cfg), nil
 }
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LimitDecision — ответ хранилища на попытку взять токен.
+type LimitDecision struct {
+	Allowed    bool
+	Remaining  int           // сколько запросов ещё можно сделать сразу
+	RetryAfter time.Duration // через сколько появится токен, если Allowed == false
+	Reset      time.Duration // через сколько корзина наполнится целиком
+}
+
+// LimiterStore хранит корзины токенов. Память процесса — MemoryLimiterStore;
+// общий бэкенд (например, Redis) для нескольких реплик реализует тот же интерфейс.
+type LimiterStore interface {
+	Take(key string, rate float64, burst int, now time.Time) LimitDecision
+}
+
+// RateLimit — настройки ограничителя: Rate токенов в секунду, не больше Burst подряд.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+	Key   func(r *http.Request) string // по умолчанию KeyByIP
+	Store LimiterStore                 // по умолчанию NewMemoryLimiterStore(10 * time.Minute)
+	Now   func() time.Time             // часы, по умолчанию time.Now; подменяются в тестах
+}
+
+// Limit — middleware токен-бакета. Каждому ответу выставляются RateLimit-Limit,
+// RateLimit-Remaining и RateLimit-Reset, а при исчерпании лимита — 429 и Retry-After.
+func Limit(cfg RateLimit) func(http.Handler) http.Handler {
+	if cfg.Rate <= 0 {
+		panic("middleware: rate limit must be positive")
+	}
+	if cfg.Key == nil {
+		cfg.Key = KeyByIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryLimiterStore(10 * time.Minute)
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	window := int(math.Ceil(float64(cfg.Burst) / cfg.Rate))
+	policy := strconv.Itoa(cfg.Burst) + ";w=" + strconv.Itoa(window)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := cfg.Store.Take(cfg.Key(r), cfg.Rate, cfg.Burst, cfg.Now())
+
+			h := w.Header()
+			h.Set("RateLimit-Policy", policy)
+			h.Set("RateLimit-Limit", strconv.Itoa(cfg.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+			if !d.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(d.RetryAfter)))
+				h.Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "rate limit exceeded"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// KeyByIP — ключ по IP клиента. За прокси перед ним нужен chimw.RealIP.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// KeyByAPIKey — ключ по заголовку header (например, X-API-Key). Отдельная корзина
+// положена только ключам из keys: заголовок приходит от клиента, и без проверки
+// каждый новый ключ давал бы новый полный бакет. Запросы без ключа или с чужим
+// ключом ограничиваются по IP.
+func KeyByAPIKey(header string, keys []string) func(*http.Request) string {
+	known := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		known[k] = true
+	}
+	return func(r *http.Request) string {
+		if k := r.Header.Get(header); known[k] {
+			return "key:" + k
+		}
+		return KeyByIP(r)
+	}
+}
+
+// KeyByRoute — общий лимит на маршрут: ключ — метод и шаблон пути из routes
+// ("GET /api/v2/tasks/{id}"), так что разные id делят одну корзину.
+func KeyByRoute(routes chi.Routes) func(*http.Request) string {
+	return func(r *http.Request) string {
+		pattern := routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+		return "route:" + r.Method + " " + pattern
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiterStore держит корзины в памяти и раз в idle/2 удаляет те,
+// к которым не обращались дольше idle: полная корзина ничем не отличается от новой.
+type MemoryLimiterStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	idle    time.Duration
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func NewMemoryLimiterStore(idle time.Duration) *MemoryLimiterStore {
+	s := &MemoryLimiterStore{buckets: make(map[string]*bucket), idle: idle, stop: make(chan struct{})}
+	go s.cleanup()
+	return s
+}
+
+func (s *MemoryLimiterStore) Take(key string, rate float64, burst int, now time.Time) LimitDecision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	}
+	b.last = now
+
+	d := LimitDecision{Allowed: b.tokens >= 1}
+	if d.Allowed {
+		b.tokens--
+	} else {
+		d.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = time.Duration((float64(burst) - b.tokens) / rate * float64(time.Second))
+	return d
+}
+
+// Len — число живых корзин.
+func (s *MemoryLimiterStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// Close останавливает фоновую очистку.
+func (s *MemoryLimiterStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+func (s *MemoryLimiterStore) cleanup() {
+	t := time.NewTicker(max(s.idle/2, time.Second))
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-t.C:
+			s.mu.Lock()
+			for k, b := range s.buckets {
+				if now.Sub(b.last) > s.idle {
+					delete(s.buckets, k)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *MemoryLimiterStore {
+	t.Helper()
+	s := NewMemoryLimiterStore(time.Hour)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestMemoryLimiterBurst(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range 3 {
+		d := s.Take("k", 1, 3, now)
+		if !d.Allowed || d.Remaining != 2-i {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, d, 2-i)
+		}
+	}
+	d := s.Take("k", 1, 3, now)
+	if d.Allowed {
+		t.Fatal("request over the burst was allowed")
+	}
+	if d.RetryAfter != time.Second || d.Reset != 3*time.Second {
+		t.Errorf("RetryAfter = %v, Reset = %v; want 1s and 3s", d.RetryAfter, d.Reset)
+	}
+	if d := s.Take("other", 1, 3, now); !d.Allowed {
+		t.Error("keys share a bucket")
+	}
+}
+
+func TestMemoryLimiterRefill(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for range 2 {
+		s.Take("k", 2, 2, now)
+	}
+
+	tests := []struct {
+		after   time.Duration
+		allowed bool
+	}{
+		{100 * time.Millisecond, false}, // 0.2 токена
+		{500 * time.Millisecond, true},  // 0.2 + 0.8 = 1 токен
+		{250 * time.Millisecond, false}, // 0.5 токена
+		{time.Hour, true},               // корзина полна, но не больше burst
+		{0, true},
+		{0, false},
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.after)
+		if d := s.Take("k", 2, 2, now); d.Allowed != tt.allowed {
+			t.Errorf("step %d (+%v): allowed = %v, want %v", i+1, tt.after, d.Allowed, tt.allowed)
+		}
+	}
+}
+
+func TestLimitResponds429(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := Limit(RateLimit{
+		Rate:  0.5,
+		Burst: 2,
+		Store: newTestStore(t),
+		Now:   func() time.Time { return now },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	do := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	for i := range 2 {
+		if rec := do(); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d status = %d", i+1, rec.Code)
+		}
+	}
+	rec := do()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	want := map[string]string{
+		"Retry-After":         "2",
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "4",
+		"RateLimit-Policy":    "2;w=4",
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	now = now.Add(2 * time.Second)
+	if rec := do(); rec.Code != http.StatusNoContent {
+		t.Errorf("after Retry-After status = %d, want 204", rec.Code)
+	}
+}
+
+func TestKeyByAPIKeyFallsBackToIP(t *testing.T) {
+	key := KeyByAPIKey("X-API-Key", []string{"secret"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	if got := key(req); got != "ip:10.0.0.1" {
+		t.Errorf("without header key = %q", got)
+	}
+	req.Header.Set("X-API-Key", "secret")
+	if got := key(req); got != "key:secret" {
+		t.Errorf("with known key = %q", got)
+	}
+	req.Header.Set("X-API-Key", "made-up")
+	if got := key(req); got != "ip:10.0.0.1" {
+		t.Errorf("with unknown key = %q", got)
+	}
+}
+
+func TestUnknownAPIKeysShareIPBucket(t *testing.T) {
+	store := newTestStore(t)
+	h := Limit(RateLimit{Rate: 1, Burst: 1, Key: KeyByAPIKey("X-API-Key", []string{"secret"}), Store: store})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		req.Header.Set("X-API-Key", "rotated-"+strconv.Itoa(i))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d: status = %d, want %d", i, rec.Code, want)
+		}
+	}
+	if n := store.Len(); n != 1 {
+		t.Errorf("buckets = %d, want 1", n)
+	}
+}
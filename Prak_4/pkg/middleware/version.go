@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// APIVersion — политика одной версии API. Нулевые даты означают «не задано».
+type APIVersion struct {
+	Name        string    // "v1"
+	Deprecation time.Time // с какого момента версия устарела (заголовок Deprecation)
+	Sunset      time.Time // когда версию планируется отключить (заголовок Sunset)
+	GoneAfter   time.Time // после этого момента версия отвечает 410 Gone
+	Successor   string    // URL замены для Link rel="successor-version"
+}
+
+type versionState struct {
+	APIVersion
+	requests atomic.Int64
+	rejected atomic.Int64
+}
+
+// Versions применяет политики версий и считает запросы к каждой из них.
+type Versions struct {
+	byName map[string]*versionState
+	order  []*versionState
+	now    func() time.Time
+}
+
+func NewVersions(vs ...APIVersion) *Versions {
+	v := &Versions{byName: make(map[string]*versionState, len(vs)), now: time.Now}
+	for _, av := range vs {
+		st := &versionState{APIVersion: av}
+		v.byName[av.Name] = st
+		v.order = append(v.order, st)
+	}
+	return v
+}
+
+// Handler — middleware для маршрутов версии name: выставляет Deprecation,
+// Sunset и Link, а после GoneAfter отвечает 410.
+func (v *Versions) Handler(name string) func(http.Handler) http.Handler {
+	st, ok := v.byName[name]
+	if !ok {
+		panic("middleware: unknown API version " + name)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			st.requests.Add(1)
+			h := w.Header()
+			h.Set("API-Version", st.Name)
+			if !st.Deprecation.IsZero() {
+				// RFC 9745: дата в формате структурированного поля
+				h.Set("Deprecation", "@"+strconv.FormatInt(st.Deprecation.Unix(), 10))
+			}
+			if !st.Sunset.IsZero() {
+				h.Set("Sunset", st.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if st.Successor != "" {
+				h.Add("Link", "<"+st.Successor+`>; rel="successor-version"`)
+			}
+
+			if !st.GoneAfter.IsZero() && v.now().After(st.GoneAfter) {
+				st.rejected.Add(1)
+				h.Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusGone)
+				_ = json.NewEncoder(w).Encode(map[string]string{
+					"error": "API " + st.Name + " was retired on " + st.GoneAfter.UTC().Format(time.RFC3339),
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+var vendorVersion = regexp.MustCompile(`^application/vnd\.tasks\.(v\d+)\+json$`)
+
+// Select направляет запросы без версии в пути (root+"/tasks") в версию из
+// Accept: application/vnd.tasks.v2+json, а без такого Accept — в def.
+// Пути с явной версией не трогает. Неизвестная версия в Accept — 406.
+// Должен стоять в r.Use до маршрутизации: chi ищет маршрут по r.URL.Path.
+func (v *Versions) Select(root, def string) func(http.Handler) http.Handler {
+	root = strings.TrimSuffix(root, "/")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, root+"/")
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			first, _, _ := strings.Cut(rest, "/")
+			if _, versioned := v.byName[first]; versioned {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept")
+			name, requested := acceptedVersion(r.Header.Get("Accept"))
+			if !requested {
+				name = def
+			}
+			if _, known := v.byName[name]; !known {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusNotAcceptable)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "unknown API version " + name})
+				return
+			}
+			r.URL.Path = root + "/" + name + "/" + rest
+			r.URL.RawPath = ""
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acceptedVersion ищет в Accept vendor-тип с версией; при нескольких
+// выбирается тот, у которого больше q.
+func acceptedVersion(accept string) (string, bool) {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		m := vendorVersion.FindStringSubmatch(mt)
+		if m == nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		if q > bestQ && q > 0 {
+			best, bestQ = m[1], q
+		}
+	}
+	return best, best != ""
+}
+
+// VersionStats — состояние и трафик одной версии.
+type VersionStats struct {
+	Version     string     `json:"version"`
+	Status      string     `json:"status"` // active, deprecated или gone
+	Deprecation *time.Time `json:"deprecation,omitempty"`
+	Sunset      *time.Time `json:"sunset,omitempty"`
+	Requests    int64      `json:"requests"`
+	Rejected    int64      `json:"rejected"`
+}
+
+func (v *Versions) Stats() []VersionStats {
+	now := v.now()
+	out := make([]VersionStats, 0, len(v.order))
+	for _, st := range v.order {
+		s := VersionStats{
+			Version:  st.Name,
+			Status:   "active",
+			Requests: st.requests.Load(),
+			Rejected: st.rejected.Load(),
+		}
+		if !st.Deprecation.IsZero() {
+			d := st.Deprecation
+			s.Deprecation = &d
+			if !now.Before(d) {
+				s.Status = "deprecated"
+			}
+		}
+		if !st.Sunset.IsZero() {
+			sun := st.Sunset
+			s.Sunset = &sun
+		}
+		if !st.GoneAfter.IsZero() && now.After(st.GoneAfter) {
+			s.Status = "gone"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// StatsHandler отдаёт Stats в JSON.
+func (v *Versions) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v.Stats())
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	deprecatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt     = time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	goneAt       = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newVersionedRouter собирает роутер как в main: Select до маршрутизации
+// и Handler внутри групп /api/v1 и /api/v2. Обработчик пишет имя версии.
+func newVersionedRouter(now time.Time) (*Versions, http.Handler) {
+	versions := NewVersions(
+		APIVersion{Name: "v1", Deprecation: deprecatedAt, Sunset: sunsetAt, GoneAfter: goneAt, Successor: "/api/v2/tasks"},
+		APIVersion{Name: "v2"},
+	)
+	versions.now = func() time.Time { return now }
+
+	r := chi.NewRouter()
+	r.Use(versions.Select("/api", "v2"))
+	for _, name := range []string{"v1", "v2"} {
+		r.Route("/api/"+name, func(api chi.Router) {
+			api.Use(versions.Handler(name))
+			api.Get("/tasks/*", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(name + " " + chi.URLParam(r, "*")))
+			})
+		})
+	}
+	return versions, r
+}
+
+func TestVersionRouting(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		code   int
+		body   string
+	}{
+		{"explicit v1", "/api/v1/tasks/1", "", http.StatusOK, "v1 1"},
+		{"explicit v2", "/api/v2/tasks/1", "", http.StatusOK, "v2 1"},
+		{"default version", "/api/tasks/1", "", http.StatusOK, "v2 1"},
+		{"accept v1", "/api/tasks/1", "application/vnd.tasks.v1+json", http.StatusOK, "v1 1"},
+		{"highest q wins", "/api/tasks/1", "application/vnd.tasks.v1+json;q=0.5, application/vnd.tasks.v2+json;q=0.9", http.StatusOK, "v2 1"},
+		{"q=0 is ignored", "/api/tasks/1", "application/vnd.tasks.v1+json;q=0", http.StatusOK, "v2 1"},
+		{"path wins over accept", "/api/v2/tasks/1", "application/vnd.tasks.v1+json", http.StatusOK, "v2 1"},
+		{"unknown version", "/api/tasks/1", "application/vnd.tasks.v9+json", http.StatusNotAcceptable, "unknown API version v9"},
+	}
+	_, h := newVersionedRouter(deprecatedAt.Add(time.Hour))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestVersionDeprecationHeaders(t *testing.T) {
+	_, h := newVersionedRouter(deprecatedAt.Add(time.Hour))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/", nil))
+	want := map[string]string{
+		"API-Version": "v1",
+		"Deprecation": "@1767225600",
+		"Sunset":      "Tue, 30 Jun 2026 00:00:00 GMT",
+		"Link":        `</api/v2/tasks>; rel="successor-version"`,
+	}
+	for k, v := range want {
+		if got := rec.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/tasks/", nil))
+	if got := rec.Header().Get("API-Version"); got != "v2" {
+		t.Errorf("v2 API-Version = %q", got)
+	}
+	for _, k := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := rec.Header().Get(k); got != "" {
+			t.Errorf("v2 has %s: %q", k, got)
+		}
+	}
+}
+
+func TestVersionGoneAfterCutoff(t *testing.T) {
+	versions, h := newVersionedRouter(goneAt.Add(time.Second))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/", nil))
+	if rec.Code != http.StatusGone {
+		t.Fatalf("status = %d, want 410", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/tasks/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("v2 status = %d, want 200", rec.Code)
+	}
+
+	stats := versions.Stats()
+	if stats[0].Status != "gone" || stats[0].Requests != 1 || stats[0].Rejected != 1 {
+		t.Errorf("v1 stats = %+v", stats[0])
+	}
+	if stats[1].Status != "active" || stats[1].Requests != 1 {
+		t.Errorf("v2 stats = %+v", stats[1])
+	}
+}
@@ -0,0 +1,579 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Коды выхода CLI
+const (
+	exitOK       = 0
+	exitError    = 1 // ошибка БД, ввода-вывода и т.п.
+	exitUsage    = 2 // неверная команда, флаг или аргумент
+	exitNotFound = 3 // хотя бы одной задачи из аргументов нет
+)
+
+// commandTimeout — сколько ждать БД на одну команду
+const commandTimeout = 30 * time.Second
+
+const usageText = `Использование: tasks <команда> [флаги] [аргументы]
+
+Команды:
+  add [--json] <title>              добавить задачу
+  list [--done=true|false] [--limit N] [--after ID] [--json]
+                                    список задач
+  done <id>...                      отметить выполненными
+  undone <id>...                    вернуть в работу
+  rm <id>...                        удалить
+  import [файл|-]                   загрузить задачи из JSON (как у export) или по одной на строку
+  export [--done=true|false] [файл|-]
+                                    выгрузить задачи в JSON
+  migrate up                        применить все новые миграции схемы
+  migrate down [N]                  откатить N последних миграций (по умолчанию 1)
+  migrate to <версия>               привести схему к версии (0 — откатить всё)
+  migrate status [--json]           список миграций и их состояние
+
+Коды выхода: 0 — успех, 1 — ошибка, 2 — неверное использование, 3 — задача не найдена.
+`
+
+// usageError — ошибка в аргументах командной строки (код выхода 2);
+// printed — сообщение уже выведено пакетом flag.
+type usageError struct {
+	msg     string
+	printed bool
+}
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]command{
+	"add":     cmdAdd,
+	"list":    cmdList,
+	"done":    func(ctx context.Context, c *cli, args []string) error { return cmdMark(ctx, c, args, true) },
+	"undone":  func(ctx context.Context, c *cli, args []string) error { return cmdMark(ctx, c, args, false) },
+	"rm":      cmdRemove,
+	"import":  cmdImport,
+	"export":  cmdExport,
+	"migrate": cmdMigrate,
+}
+
+// cli — окружение команды; соединение с БД открывается только при первом обращении,
+// чтобы ошибки в аргументах не требовали доступной базы.
+type cli struct {
+	dsn    string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	db     *sql.DB
+}
+
+func (c *cli) repo() (*Repo, error) {
+	if c.db == nil {
+		db, err := openDB(c.dsn)
+		if err != nil {
+			return nil, fmt.Errorf("openDB: %w", err)
+		}
+		c.db = db
+	}
+	return NewRepo(c.db), nil
+}
+
+func (c *cli) close() {
+	if c.db != nil {
+		c.db.Close()
+	}
+}
+
+// run выполняет команду из args и возвращает код выхода.
+func (c *cli) run(ctx context.Context, args []string) int {
+	defer c.close()
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(c.stderr, usageText)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n%s", args[0], usageText)
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	err := cmd(ctx, c, args[1:])
+	var ue usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &ue):
+		if !ue.printed {
+			fmt.Fprintf(c.stderr, "%s: %v\n", args[0], err)
+		}
+		return exitUsage
+	case errors.Is(err, ErrNotFound):
+		fmt.Fprintf(c.stderr, "%s: %v\n", args[0], err)
+		return exitNotFound
+	default:
+		fmt.Fprintf(c.stderr, "%s: %v\n", args[0], err)
+		return exitError
+	}
+}
+
+// flags создаёт набор флагов подкоманды; ошибки разбора выводит сам flag.
+func (c *cli) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parse разбирает флаги; ошибка разбора становится usageError.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{msg: err.Error(), printed: true}
+	}
+	return nil
+}
+
+// doneFlag — необязательный фильтр --done: не задан, true или false
+type doneFlag struct {
+	set   bool
+	value bool
+}
+
+func (f *doneFlag) String() string {
+	if !f.set {
+		return ""
+	}
+	return strconv.FormatBool(f.value)
+}
+
+func (f *doneFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.New("want true or false")
+	}
+	f.set, f.value = true, v
+	return nil
+}
+
+// ptr — фильтр для ListParams.Done и Count: nil, если --done не задан.
+func (f *doneFlag) ptr() *bool {
+	if !f.set {
+		return nil
+	}
+	return &f.value
+}
+
+// IsBoolFlag позволяет писать просто --done вместо --done=true.
+func (f *doneFlag) IsBoolFlag() bool { return true }
+
+func cmdAdd(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("add")
+	asJSON := fs.Bool("json", false, "вывести результат в JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	title := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if title == "" {
+		return usagef("title is required")
+	}
+	repo, err := c.repo()
+	if err != nil {
+		return err
+	}
+	id, err := repo.CreateTask(ctx, title)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		t, err := repo.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return writeJSON(c.stdout, t)
+	}
+	fmt.Fprintf(c.stdout, "added #%d %s\n", id, title)
+	return nil
+}
+
+func cmdList(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("list")
+	var done doneFlag
+	fs.Var(&done, "done", "только выполненные (true) или невыполненные (false)")
+	limit := fs.Int("limit", 0, "не больше N задач (0 — все)")
+	after := fs.Int("after", 0, "начать с задач с id больше N")
+	asJSON := fs.Bool("json", false, "вывести список в JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if *limit < 0 || *limit > MaxListLimit {
+		return usagef("--limit must be between 0 and %d", MaxListLimit)
+	}
+	if *after < 0 {
+		return usagef("--after must be non-negative")
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
+	}
+
+	var tasks []Task
+	next := 0
+	if *limit == 0 {
+		all, err := loadTasks(ctx, c, done, *after)
+		if err != nil {
+			return err
+		}
+		tasks = all
+	} else {
+		repo, err := c.repo()
+		if err != nil {
+			return err
+		}
+		page, err := repo.List(ctx, ListParams{Done: done.ptr(), AfterID: *after, Limit: *limit})
+		if err != nil {
+			return err
+		}
+		tasks, next = page.Items, page.NextAfterID
+	}
+
+	if *asJSON {
+		return writeJSON(c.stdout, tasks)
+	}
+	for _, t := range tasks {
+		fmt.Fprintf(c.stdout, "#%d | %-24s | done=%-5v | %s\n",
+			t.ID, t.Title, t.Done, t.CreatedAt.Format(time.RFC3339))
+	}
+	if next > 0 {
+		fmt.Fprintf(c.stderr, "more: list --limit %d --after %d\n", *limit, next)
+	}
+	return nil
+}
+
+// cmdMark обрабатывает done и undone. Несуществующие id не прерывают работу:
+// остальные задачи обновляются, а команда завершается с кодом 3.
+func cmdMark(ctx context.Context, c *cli, args []string, done bool) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+	repo, err := c.repo()
+	if err != nil {
+		return err
+	}
+	return eachID(c, ids, func(id int) error { return repo.MarkDone(ctx, id, done) })
+}
+
+func cmdRemove(ctx context.Context, c *cli, args []string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+	repo, err := c.repo()
+	if err != nil {
+		return err
+	}
+	return eachID(c, ids, func(id int) error { return repo.Delete(ctx, id) })
+}
+
+// cmdImport создаёт задачи одной транзакцией (CreateMany): либо все, либо ни одной.
+// Задачи всегда создаются невыполненными, id и даты из файла не переносятся.
+func cmdImport(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("import")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usagef("want at most one file")
+	}
+	in, closeIn, err := c.input(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeIn()
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("read input: %w", err)
+	}
+	titles, err := parseTitles(data)
+	if err != nil {
+		return err
+	}
+	if len(titles) == 0 {
+		fmt.Fprintln(c.stdout, "nothing to import")
+		return nil
+	}
+	repo, err := c.repo()
+	if err != nil {
+		return err
+	}
+	if err := repo.CreateMany(ctx, titles); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "imported %d tasks\n", len(titles))
+	return nil
+}
+
+func cmdExport(ctx context.Context, c *cli, args []string) error {
+	fs := c.flags("export")
+	var done doneFlag
+	fs.Var(&done, "done", "только выполненные (true) или невыполненные (false)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return usagef("want at most one file")
+	}
+	tasks, err := loadTasks(ctx, c, done, 0)
+	if err != nil {
+		return err
+	}
+	path := fs.Arg(0)
+	if path == "" || path == "-" {
+		return writeJSON(c.stdout, tasks)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f, tasks); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "exported %d tasks to %s\n", len(tasks), path)
+	return nil
+}
+
+// loadTasks — все задачи с id > after (или только с нужным done), упорядоченные по id.
+// Читает страницами по MaxListLimit, чтобы не держать один длинный запрос.
+func loadTasks(ctx context.Context, c *cli, done doneFlag, after int) ([]Task, error) {
+	repo, err := c.repo()
+	if err != nil {
+		return nil, err
+	}
+	tasks := []Task{} // в JSON — [], а не null
+	p := ListParams{Done: done.ptr(), AfterID: after, Limit: MaxListLimit}
+	for {
+		page, err := repo.List(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, page.Items...)
+		if page.NextAfterID == 0 {
+			return tasks, nil
+		}
+		p.AfterID = page.NextAfterID
+	}
+}
+
+func parseIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, usagef("at least one id is required")
+	}
+	ids := make([]int, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.Atoi(strings.TrimPrefix(a, "#"))
+		if err != nil || id <= 0 {
+			return nil, usagef("invalid id %q", a)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// eachID применяет fn ко всем id; ErrNotFound запоминается, любая другая ошибка прерывает обход.
+func eachID(c *cli, ids []int, fn func(id int) error) error {
+	var missing []string
+	for _, id := range ids {
+		err := fn(id)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			missing = append(missing, "#"+strconv.Itoa(id))
+		case err != nil:
+			return fmt.Errorf("#%d: %w", id, err)
+		default:
+			fmt.Fprintf(c.stdout, "ok #%d\n", id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// input открывает файл или stdin для пустого пути и "-".
+func (c *cli) input(path string) (io.Reader, func(), error) {
+	if path == "" || path == "-" {
+		return c.stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
+
+// parseTitles понимает JSON-массив задач (формат export) или строк,
+// иначе считает каждую непустую строку отдельным заголовком.
+func parseTitles(data []byte) ([]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	var titles []string
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, usagef("invalid JSON: %v", err)
+		}
+		for i, raw := range items {
+			var t struct {
+				Title string `json:"title"`
+			}
+			if err := json.Unmarshal(raw, &t.Title); err != nil {
+				if err := json.Unmarshal(raw, &t); err != nil {
+					return nil, usagef("item %d: want a string or an object with title", i)
+				}
+			}
+			title := strings.TrimSpace(t.Title)
+			if title == "" {
+				return nil, usagef("item %d: empty title", i)
+			}
+			titles = append(titles, title)
+		}
+		return titles, nil
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if title := strings.TrimSpace(sc.Text()); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles, sc.Err()
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func cmdMigrate(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return usagef("want up, down, to or status")
+	}
+	sub, rest := args[0], args[1:]
+	fs := c.flags("migrate " + sub)
+	asJSON := fs.Bool("json", false, "вывести состояние в JSON")
+	if err := parse(fs, rest); err != nil {
+		return err
+	}
+	if sub != "status" && *asJSON {
+		return usagef("--json is only supported by migrate status")
+	}
+
+	var run func(m *Migrator) ([]MigrationStatus, error)
+	switch sub {
+	case "up":
+		if fs.NArg() > 0 {
+			return usagef("unexpected argument %q", fs.Arg(0))
+		}
+		run = func(m *Migrator) ([]MigrationStatus, error) { return m.Up(ctx) }
+	case "down":
+		steps := 1
+		if fs.NArg() > 1 {
+			return usagef("want at most one argument")
+		}
+		if fs.NArg() == 1 {
+			n, err := strconv.Atoi(fs.Arg(0))
+			if err != nil || n < 1 {
+				return usagef("invalid number of steps %q", fs.Arg(0))
+			}
+			steps = n
+		}
+		run = func(m *Migrator) ([]MigrationStatus, error) { return m.Down(ctx, steps) }
+	case "to":
+		if fs.NArg() != 1 {
+			return usagef("want exactly one version")
+		}
+		v, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil || v < 0 {
+			return usagef("invalid version %q", fs.Arg(0))
+		}
+		run = func(m *Migrator) ([]MigrationStatus, error) { return m.To(ctx, v) }
+	case "status":
+		if fs.NArg() > 0 {
+			return usagef("unexpected argument %q", fs.Arg(0))
+		}
+	default:
+		return usagef("unknown subcommand %q: want up, down, to or status", sub)
+	}
+
+	if _, err := c.repo(); err != nil {
+		return err
+	}
+	m, err := NewEmbeddedMigrator(c.db)
+	if err != nil {
+		return err
+	}
+
+	if sub == "status" {
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeJSON(c.stdout, list)
+		}
+		for _, st := range list {
+			state := "pending"
+			if st.AppliedAt != nil {
+				state = "applied " + st.AppliedAt.Format(time.RFC3339)
+			}
+			if st.Unknown {
+				state += " (no migration file)"
+			}
+			fmt.Fprintf(c.stdout, "%04d_%-24s %s\n", st.Version, st.Name, state)
+		}
+		return nil
+	}
+
+	steps, err := run(m)
+	for _, st := range steps {
+		verb := "reverted"
+		if st.AppliedAt != nil {
+			verb = "applied"
+		}
+		fmt.Fprintf(c.stdout, "%s %04d_%s\n", verb, st.Version, st.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Fprintln(c.stdout, "schema is up to date")
+	}
+	return nil
+}
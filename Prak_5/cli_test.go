@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// unreachableDSN — порт 1 на localhost: соединение отклоняется сразу, так что
+// команды, дошедшие до БД, быстро завершаются с кодом 1.
+const unreachableDSN = "postgres://tasks@127.0.0.1:1/tasks?sslmode=disable&connect_timeout=1"
+
+func runCLI(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	c := &cli{dsn: unreachableDSN, stdin: strings.NewReader(stdin), stdout: &out, stderr: &errOut}
+	code = c.run(context.Background(), args)
+	return code, out.String(), errOut.String()
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		stderr string
+	}{
+		{"no command", nil, "", exitUsage, "Использование"},
+		{"help", []string{"help"}, "", exitOK, "Использование"},
+		{"unknown command", []string{"frobnicate"}, "", exitUsage, `unknown command "frobnicate"`},
+		{"add without title", []string{"add"}, "", exitUsage, "add: title is required"},
+		{"add blank title", []string{"add", "  "}, "", exitUsage, "title is required"},
+		{"unknown flag", []string{"add", "--bogus", "x"}, "", exitUsage, "flag provided but not defined: -bogus"},
+		{"flag help", []string{"list", "-h"}, "", exitOK, "-limit"},
+		{"negative limit", []string{"list", "--limit", "-1"}, "", exitUsage, "--limit must be between 0 and 1000"},
+		{"limit too big", []string{"list", "--limit", "1001"}, "", exitUsage, "--limit must be between"},
+		{"negative after", []string{"list", "--after=-5"}, "", exitUsage, "--after must be non-negative"},
+		{"bad done", []string{"list", "--done=maybe"}, "", exitUsage, "want true or false"},
+		{"list extra argument", []string{"list", "extra"}, "", exitUsage, `unexpected argument "extra"`},
+		{"done without ids", []string{"done"}, "", exitUsage, "at least one id is required"},
+		{"rm bad id", []string{"rm", "1", "abc"}, "", exitUsage, `invalid id "abc"`},
+		{"undone zero id", []string{"undone", "0"}, "", exitUsage, `invalid id "0"`},
+		{"import two files", []string{"import", "a", "b"}, "", exitUsage, "want at most one file"},
+		{"import bad item", []string{"import"}, "[1]", exitUsage, "item 0: want a string or an object with title"},
+		{"import broken json", []string{"import", "-"}, "[{", exitUsage, "invalid JSON"},
+		{"import missing file", []string{"import", "/nonexistent/tasks.json"}, "", exitError, "no such file"},
+		{"export two files", []string{"export", "a", "b"}, "", exitUsage, "want at most one file"},
+		{"migrate without subcommand", []string{"migrate"}, "", exitUsage, "want up, down, to or status"},
+		{"migrate unknown subcommand", []string{"migrate", "sideways"}, "", exitUsage, `unknown subcommand "sideways"`},
+		{"migrate up json", []string{"migrate", "up", "--json"}, "", exitUsage, "--json is only supported by migrate status"},
+		{"migrate down zero", []string{"migrate", "down", "0"}, "", exitUsage, `invalid number of steps "0"`},
+		{"migrate to without version", []string{"migrate", "to"}, "", exitUsage, "want exactly one version"},
+		{"migrate to bad version", []string{"migrate", "to", "v2"}, "", exitUsage, `invalid version "v2"`},
+		{"database unavailable", []string{"list"}, "", exitError, "list: openDB:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, tt.stdin, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d; stderr: %s", code, tt.code, stderr)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr = %q, want %q", stderr, tt.stderr)
+			}
+		})
+	}
+}
+
+func TestCLIFlagErrorPrintedOnce(t *testing.T) {
+	_, _, stderr := runCLI(t, "", "list", "--bogus")
+	if n := strings.Count(stderr, "flag provided but not defined"); n != 1 {
+		t.Errorf("flag error printed %d times:\n%s", n, stderr)
+	}
+}
+
+func TestCLIImportNothing(t *testing.T) {
+	code, stdout, _ := runCLI(t, "\n  \n", "import")
+	if code != exitOK || stdout != "nothing to import\n" {
+		t.Errorf("code = %d, stdout = %q", code, stdout)
+	}
+}
+
+func TestParseTitles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"lines", "buy milk\n\n  call mom  \r\nwrite report", []string{"buy milk", "call mom", "write report"}},
+		{"bom before lines", "\ufeffbuy milk\n", []string{"buy milk"}},
+		{"json strings", `["buy milk", " call mom "]`, []string{"buy milk", "call mom"}},
+		{"export format", `[{"id": 7, "title": "buy milk", "done": true}, {"title": "call mom"}]`, []string{"buy milk", "call mom"}},
+		{"mixed json", `["buy milk", {"title": "call mom"}]`, []string{"buy milk", "call mom"}},
+		{"bom before json", "\ufeff  [\"buy milk\"]", []string{"buy milk"}},
+		{"empty array", "[]", nil},
+		{"text starting with a bracket", "buy milk\n[urgent] call mom", []string{"buy milk", "[urgent] call mom"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTitles([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTitlesErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`[{"title": "ok"}, {"title": "  "}]`, "item 1: empty title"},
+		{`[""]`, "item 0: empty title"},
+		{`[{"id": 1}]`, "item 0: empty title"},
+		{`[42]`, "item 0: want a string or an object with title"},
+		{`["a",`, "invalid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := parseTitles([]byte(tt.input))
+			var ue usageError
+			if !errors.As(err, &ue) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want usage error %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []int
+		ok   bool
+	}{
+		{[]string{"1"}, []int{1}, true},
+		{[]string{"#3", "10", "3"}, []int{3, 10, 3}, true},
+		{nil, nil, false},
+		{[]string{"1", "x"}, nil, false},
+		{[]string{"-2"}, nil, false},
+		{[]string{"0"}, nil, false},
+		{[]string{"1.5"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			got, err := parseIDs(tt.args)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok = %v", err, tt.ok)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoneFlag(t *testing.T) {
+	var f doneFlag
+	if f.ptr() != nil || f.String() != "" {
+		t.Fatal("unset flag must not filter")
+	}
+	if err := f.Set("false"); err != nil {
+		t.Fatal(err)
+	}
+	if p := f.ptr(); p == nil || *p {
+		t.Errorf("ptr after Set(false) = %v", p)
+	}
+	if err := f.Set("yes"); err == nil {
+		t.Error("Set accepted yes")
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockKey — ключ pg_advisory_lock: пока один процесс применяет миграции,
+// остальные ждут, а не выполняют те же скрипты параллельно.
+const migrationLockKey int64 = 0x7461736b73 // "tasks"
+
+// Имя файла миграции: <версия>_<название>.<up|down>.sql, например 0001_create_tasks.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus — состояние одной миграции для команды migrate status
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Unknown — версия есть в schema_migrations, но файла для неё нет
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Migrator применяет миграции из fsys к db. Каждая миграция выполняется в своей
+// транзакции вместе с записью в schema_migrations, поэтому упавший скрипт не оставляет
+// схему в промежуточном состоянии.
+type Migrator struct {
+	db         *sql.DB
+	migrations []migration
+}
+
+// NewMigrator читает миграции из корня fsys; для встроенных — fs.Sub(migrationsFS, "migrations").
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	ms, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: ms}, nil
+}
+
+// NewEmbeddedMigrator — Migrator со встроенными в бинарник миграциями.
+func NewEmbeddedMigrator(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrator(db, sub)
+}
+
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	byVersion := map[int64]*migration{}
+	files := map[string]string{} // "<версия> up|down" -> имя файла, ловит 0001_x и 001_x
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: want name like 0001_name.up.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: version must be positive", e.Name())
+		}
+		key := strconv.FormatInt(version, 10) + " " + m[3]
+		if prev, ok := files[key]; ok {
+			return nil, fmt.Errorf("migration %d: duplicate %s files %s and %s", version, m[3], prev, e.Name())
+		}
+		files[key] = e.Name()
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		mg := byVersion[version]
+		if mg == nil {
+			mg = &migration{Version: version, Name: m[2]}
+			byVersion[version] = mg
+		}
+		if mg.Name != m[2] {
+			return nil, fmt.Errorf("migration %d: names %q and %q differ", version, mg.Name, m[2])
+		}
+		if m[3] == "up" {
+			mg.Up = string(body)
+		} else {
+			mg.Down = string(body)
+		}
+	}
+
+	out := make([]migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" || mg.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s: both up and down files are required", mg.Version, mg.Name)
+		}
+		out = append(out, *mg)
+	}
+	slices.SortFunc(out, func(a, b migration) int { return cmp.Compare(a.Version, b.Version) })
+	return out, nil
+}
+
+// Latest — номер последней известной миграции (0, если миграций нет).
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применяет все ещё не применённые миграции.
+func (m *Migrator) Up(ctx context.Context) ([]MigrationStatus, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down откатывает steps последних применённых миграций.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]MigrationStatus, error) {
+	if steps < 1 {
+		return nil, errors.New("steps must be positive")
+	}
+	return m.locked(ctx, func(conn *sql.Conn) ([]MigrationStatus, error) {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		versions := sortedKeys(applied)
+		target := int64(0)
+		if steps < len(versions) {
+			target = versions[len(versions)-steps-1]
+		}
+		return m.migrate(ctx, conn, applied, target)
+	})
+}
+
+// To приводит схему к версии target: применяет недостающие миграции до неё
+// включительно и откатывает все более новые. To(ctx, 0) откатывает всё.
+func (m *Migrator) To(ctx context.Context, target int64) ([]MigrationStatus, error) {
+	if target != 0 && !slices.ContainsFunc(m.migrations, func(mg migration) bool { return mg.Version == target }) {
+		return nil, fmt.Errorf("unknown migration version %d", target)
+	}
+	return m.locked(ctx, func(conn *sql.Conn) ([]MigrationStatus, error) {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		return m.migrate(ctx, conn, applied, target)
+	})
+}
+
+// Status возвращает все известные миграции и отметку о применении. Таблицу
+// schema_migrations не создаёт: на чистой базе все миграции — в ожидании.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL;`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	applied := map[int64]appliedMigration{}
+	if exists {
+		var err error
+		if applied, err = appliedVersions(ctx, m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		st := MigrationStatus{Version: mg.Version, Name: mg.Name}
+		if a, ok := applied[mg.Version]; ok {
+			st.AppliedAt = &a.At
+			delete(applied, mg.Version)
+		}
+		out = append(out, st)
+	}
+	for _, v := range sortedKeys(applied) {
+		a := applied[v]
+		out = append(out, MigrationStatus{Version: v, Name: a.Name, AppliedAt: &a.At, Unknown: true})
+	}
+	return out, nil
+}
+
+// locked выполняет fn на отдельном соединении под advisory lock: блокировка
+// принадлежит сессии, поэтому захват, миграции и освобождение идут через одно соединение.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) ([]MigrationStatus, error)) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, migrationLockKey); err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		// отдельный контекст: блокировку нужно снять, даже если ctx уже отменён
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock($1);`, migrationLockKey)
+	}()
+
+	const q = `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT      PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	);`
+	if _, err := conn.ExecContext(ctx, q); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// migrate применяет или откатывает миграции, пока схема не достигнет target.
+// Возвращает выполненные шаги в порядке выполнения.
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, applied map[int64]appliedMigration, target int64) ([]MigrationStatus, error) {
+	for _, v := range sortedKeys(applied) {
+		if !slices.ContainsFunc(m.migrations, func(mg migration) bool { return mg.Version == v }) {
+			return nil, fmt.Errorf("database has migration %d which this binary does not know", v)
+		}
+	}
+
+	var done []MigrationStatus
+	// откат — от новых к старым
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok || mg.Version <= target {
+			continue
+		}
+		if err := runMigration(ctx, conn, mg.Down, `DELETE FROM schema_migrations WHERE version = $1;`, mg.Version); err != nil {
+			return done, fmt.Errorf("migrate down %04d_%s: %w", mg.Version, mg.Name, err)
+		}
+		done = append(done, MigrationStatus{Version: mg.Version, Name: mg.Name})
+	}
+	// применение — от старых к новым
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok || mg.Version > target {
+			continue
+		}
+		now := time.Now().UTC()
+		if err := runMigration(ctx, conn, mg.Up, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3);`, mg.Version, mg.Name, now); err != nil {
+			return done, fmt.Errorf("migrate up %04d_%s: %w", mg.Version, mg.Name, err)
+		}
+		done = append(done, MigrationStatus{Version: mg.Version, Name: mg.Name, AppliedAt: &now})
+	}
+	return done, nil
+}
+
+// runMigration выполняет скрипт и запись в schema_migrations одной транзакцией.
+func runMigration(ctx context.Context, conn *sql.Conn, script, record string, args ...any) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type appliedMigration struct {
+	Name string
+	At   time.Time
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func appliedVersions(ctx context.Context, q queryer) (map[int64]appliedMigration, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, name, applied_at FROM schema_migrations;`)
+	if err != nil {
+		return nil, fmt.Errorf("read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	out := map[int64]appliedMigration{}
+	for rows.Next() {
+		var v int64
+		var a appliedMigration
+		if err := rows.Scan(&v, &a.Name, &a.At); err != nil {
+			return nil, fmt.Errorf("read schema_migrations: %w", err)
+		}
+		out[v] = a
+	}
+	return out, rows.Err()
+}
+
+func sortedKeys(m map[int64]appliedMigration) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func sqlFiles(names ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, n := range names {
+		fsys[n] = &fstest.MapFile{Data: []byte("-- " + n)}
+	}
+	return fsys
+}
+
+func TestLoadMigrationsOrdersAndPairs(t *testing.T) {
+	fsys := sqlFiles(
+		"0010_add_index.down.sql", "0010_add_index.up.sql",
+		"0002_add_done.up.sql", "0002_add_done.down.sql",
+		"0001_create_tasks.up.sql", "0001_create_tasks.down.sql",
+	)
+	fsys["README.md"] = &fstest.MapFile{Data: []byte("не миграция")}
+	fsys["old"] = &fstest.MapFile{Mode: fs.ModeDir | 0o755} // каталог пропускается
+
+	ms, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		version int64
+		name    string
+	}{{1, "create_tasks"}, {2, "add_done"}, {10, "add_index"}}
+	if len(ms) != len(want) {
+		t.Fatalf("got %d migrations, want %d", len(ms), len(want))
+	}
+	for i, w := range want {
+		m := ms[i]
+		if m.Version != w.version || m.Name != w.name {
+			t.Errorf("migration %d = %d_%s, want %d_%s", i, m.Version, m.Name, w.version, w.name)
+		}
+		if !strings.Contains(m.Up, m.Name+".up.sql") || !strings.Contains(m.Down, m.Name+".down.sql") {
+			t.Errorf("migration %d: up %q, down %q are not paired", m.Version, m.Up, m.Down)
+		}
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		err   string
+	}{
+		{"missing down", []string{"0001_a.up.sql"}, "both up and down files are required"},
+		{"missing up", []string{"0001_a.down.sql"}, "both up and down files are required"},
+		{"name mismatch", []string{"0001_a.up.sql", "0001_b.down.sql"}, `names "a" and "b" differ`},
+		{"duplicate version", []string{"0001_a.up.sql", "001_a.up.sql", "0001_a.down.sql"}, "duplicate up files"},
+		{"duplicate version down", []string{"0001_a.up.sql", "0001_a.down.sql", "1_a.down.sql"}, "duplicate down files"},
+		{"bad name", []string{"create_tasks.sql"}, "want name like 0001_name.up.sql"},
+		{"bad direction", []string{"0001_a.redo.sql"}, "want name like"},
+		{"zero version", []string{"0000_a.up.sql", "0000_a.down.sql"}, "version must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(sqlFiles(tt.files...))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	m, err := NewEmbeddedMigrator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Latest() < 1 {
+		t.Errorf("Latest = %d, want embedded migrations", m.Latest())
+	}
+}
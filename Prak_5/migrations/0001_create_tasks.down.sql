@@ -0,0 +1,8 @@
+-- Таблица, созданная до миграций, остаётся вместе с данными.
+DO $$
+BEGIN
+    IF obj_description(to_regclass('tasks'), 'pg_class') = 'created by migration 0001_create_tasks' THEN
+        DROP TABLE tasks;
+    END IF;
+END
+$$;
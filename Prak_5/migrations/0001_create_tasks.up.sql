@@ -0,0 +1,15 @@
+-- Базы, где таблицу создали вручную по README, принимают миграцию без изменений.
+-- Созданную здесь таблицу помечаем комментарием: откат удаляет только её.
+DO $$
+BEGIN
+    IF to_regclass('tasks') IS NULL THEN
+        CREATE TABLE tasks (
+            id         SERIAL PRIMARY KEY,
+            title      TEXT        NOT NULL,
+            done       BOOLEAN     NOT NULL DEFAULT FALSE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        );
+        COMMENT ON TABLE tasks IS 'created by migration 0001_create_tasks';
+    END IF;
+END
+$$;
@@ -3,88 +3,177 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// ErrNotFound — задачи с таким id нет
+var ErrNotFound = errors.New("task not found")
+
+// Размер страницы List и SearchByTitle
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 1000
+)
+
 // Task — модель для сканирования результатов SELECT
 type Task struct {
-	ID        int
-	Title     string
-	Done      bool
-	CreatedAt time.Time
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
+// ListParams — параметры List. Пагинация по ключу: следующая страница
+// запрашивается с AfterID = NextAfterID предыдущей.
+type ListParams struct {
+	Done    *bool // nil — все задачи
+	AfterID int   // вернуть задачи с id > AfterID
+	Limit   int   // 0 — DefaultListLimit, не больше MaxListLimit
+}
+
+// Page — страница List; NextAfterID == 0, если дальше задач нет.
+type Page struct {
+	Items       []Task `json:"items"`
+	NextAfterID int    `json:"next_after_id,omitempty"`
+}
+
+// Repo — доступ к таблице tasks. Все ошибки драйвера оборачиваются именем
+// метода ("Repo.Update: ..."), ErrNotFound проверяется через errors.Is.
 type Repo struct {
 	DB *sql.DB
 }
 
 func NewRepo(db *sql.DB) *Repo { return &Repo{DB: db} }
 
+// opError добавляет к ошибке имя операции; nil остаётся nil.
+func opError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("Repo.%s: %w", op, err)
+}
+
+const taskColumns = `id, title, done, created_at`
+
 // CreateTask — параметризованный INSERT с возвратом id
 func (r *Repo) CreateTask(ctx context.Context, title string) (int, error) {
 	var id int
 	const q = `INSERT INTO tasks (title) VALUES ($1) RETURNING id;`
 	err := r.DB.QueryRowContext(ctx, q, title).Scan(&id)
-	return id, err
+	return id, opError("CreateTask", err)
 }
 
 // ListTasks — базовый SELECT всех задач (демо для занятия)
 func (r *Repo) ListTasks(ctx context.Context) ([]Task, error) {
-	const q = `SELECT id, title, done, created_at FROM tasks ORDER BY id;`
-	rows, err := r.DB.QueryContext(ctx, q)
-	if err != nil {
-		return nil, err
+	const q = `SELECT ` + taskColumns + ` FROM tasks ORDER BY id;`
+	out, err := r.query(ctx, q)
+	return out, opError("ListTasks", err)
+}
+
+func (r *Repo) ListDone(ctx context.Context, done bool) ([]Task, error) {
+	const query = "SELECT " + taskColumns + " FROM tasks WHERE done = $1 ORDER BY id;"
+	out, err := r.query(ctx, query, done)
+	return out, opError("ListDone", err)
+}
+
+// List — страница задач по возрастанию id. В отличие от OFFSET, пагинация
+// по ключу не пропускает и не повторяет задачи, если таблица меняется между запросами.
+func (r *Repo) List(ctx context.Context, p ListParams) (Page, error) {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
 	}
-	defer rows.Close()
+	limit = min(limit, MaxListLimit)
 
-	var out []Task
-	for rows.Next() {
-		var t Task
-		if err := rows.Scan(&t.ID, &t.Title, &t.Done, &t.CreatedAt); err != nil {
-			return nil, err
-		}
-		out = append(out, t)
+	where, args := []string{"id > $1"}, []any{p.AfterID}
+	if p.Done != nil {
+		args = append(args, *p.Done)
+		where = append(where, fmt.Sprintf("done = $%d", len(args)))
 	}
-	return out, rows.Err()
-}
+	// на одну строку больше, чтобы узнать, есть ли следующая страница
+	args = append(args, limit+1)
+	q := fmt.Sprintf("SELECT %s FROM tasks WHERE %s ORDER BY id LIMIT $%d;",
+		taskColumns, strings.Join(where, " AND "), len(args))
 
-func (r *Repo) ListDone(ctx context.Context, done bool) ([]Task, error) {
-	const query = "SELECT id, title, done, created_at FROM tasks WHERE done = $1;"
-	rows, err := r.DB.QueryContext(ctx, query, done)
+	items, err := r.query(ctx, q, args...)
 	if err != nil {
-		return nil, err
+		return Page{}, opError("List", err)
 	}
-	defer rows.Close()
-	var out []Task
-	for rows.Next() {
-		var t Task
-		if err := rows.Scan(&t.ID, &t.Title, &t.Done, &t.CreatedAt); err != nil {
-			return nil, err
-		}
-		out = append(out, t)
+	page := Page{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextAfterID = page.Items[limit-1].ID
 	}
-	return out, rows.Err()
+	if page.Items == nil {
+		page.Items = []Task{}
+	}
+	return page, nil
+}
+
+// Count — число задач; done == nil — всех, иначе только с таким done.
+func (r *Repo) Count(ctx context.Context, done *bool) (int, error) {
+	var n int
+	var err error
+	if done == nil {
+		err = r.DB.QueryRowContext(ctx, `SELECT count(*) FROM tasks;`).Scan(&n)
+	} else {
+		err = r.DB.QueryRowContext(ctx, `SELECT count(*) FROM tasks WHERE done = $1;`, *done).Scan(&n)
+	}
+	return n, opError("Count", err)
+}
+
+// SearchByTitle — задачи, в title которых есть подстрока query (без учёта регистра).
+// % и _ в query ищутся буквально. limit <= 0 — DefaultListLimit.
+func (r *Repo) SearchByTitle(ctx context.Context, query string, limit int) ([]Task, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+	const q = `SELECT ` + taskColumns + ` FROM tasks WHERE title ILIKE $1 ESCAPE '\' ORDER BY id LIMIT $2;`
+	out, err := r.query(ctx, q, "%"+escapeLike(query)+"%", limit)
+	return out, opError("SearchByTitle", err)
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string { return likeEscaper.Replace(s) }
+
+// FindByID — задача по id или ErrNotFound
 func (r *Repo) FindByID(ctx context.Context, id int) (*Task, error) {
-	const query = "SELECT id, title, done, created_at FROM tasks WHERE id = $1;"
-	rows, err := r.DB.QueryContext(ctx, query, id)
+	const query = "SELECT " + taskColumns + " FROM tasks WHERE id = $1;"
+	var t Task
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&t.ID, &t.Title, &t.Done, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, opError("FindByID", ErrNotFound)
+	}
 	if err != nil {
-		return nil, err
+		return nil, opError("FindByID", err)
 	}
-	defer rows.Close()
-	var out *Task
-	if err := rows.Scan(&out.ID, &out.Title, &out.Done, &out.CreatedAt); err != nil {
-		return nil, err
+	return &t, nil
+}
+
+// Update — меняет title и done, возвращает обновлённую задачу или ErrNotFound
+func (r *Repo) Update(ctx context.Context, id int, title string, done bool) (*Task, error) {
+	const q = `UPDATE tasks SET title = $2, done = $3 WHERE id = $1 RETURNING ` + taskColumns + `;`
+	var t Task
+	err := r.DB.QueryRowContext(ctx, q, id, title, done).Scan(&t.ID, &t.Title, &t.Done, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, opError("Update", ErrNotFound)
 	}
-	return out, rows.Err()
+	if err != nil {
+		return nil, opError("Update", err)
+	}
+	return &t, nil
 }
 
-func (r *Repo) CreateMany(ctx context.Context, titles []string) error {
+func (r *Repo) CreateMany(ctx context.Context, titles []string) (err error) {
 	// 1. Начинаем транзакцию
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return opError("CreateMany", err)
 	}
 	// Eсли функция завершится с ошибкой (до Commit),
 	// транзакция будет автоматически отменена.
@@ -102,11 +191,62 @@ func (r *Repo) CreateMany(ctx context.Context, titles []string) error {
 	for _, title := range titles {
 		_, err = tx.ExecContext(ctx, q, title)
 		if err != nil {
-			return err
+			return opError("CreateMany", err)
 		}
 	}
 
 	// Фиксируем изменения (если все успешно)
 	err = tx.Commit()
-	return err
+	return opError("CreateMany", err)
+}
+
+// MarkDone — отмечает задачу выполненной (done=true) или возвращает в работу
+func (r *Repo) MarkDone(ctx context.Context, id int, done bool) error {
+	const q = `UPDATE tasks SET done = $2 WHERE id = $1;`
+	res, err := r.DB.ExecContext(ctx, q, id, done)
+	if err != nil {
+		return opError("MarkDone", err)
+	}
+	return opError("MarkDone", affected(res))
+}
+
+// Delete — удаляет задачу по id
+func (r *Repo) Delete(ctx context.Context, id int) error {
+	const q = `DELETE FROM tasks WHERE id = $1;`
+	res, err := r.DB.ExecContext(ctx, q, id)
+	if err != nil {
+		return opError("Delete", err)
+	}
+	return opError("Delete", affected(res))
+}
+
+// affected возвращает ErrNotFound, если запрос не затронул ни одной строки
+func affected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// query выполняет SELECT по taskColumns и сканирует все строки
+func (r *Repo) query(ctx context.Context, q string, args ...any) ([]Task, error) {
+	rows, err := r.DB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Task
+	for rows.Next() {
+		var t Task
+		if err := rows.Scan(&t.ID, &t.Title, &t.Done, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
 }
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var taskRowColumns = []string{"id", "title", "done", "created_at"}
+
+var createdAt = time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// newMockRepo — Repo поверх sqlmock; SQL сравнивается дословно.
+func newMockRepo(t *testing.T) (*Repo, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return NewRepo(db), mock
+}
+
+func taskRows(ids ...int) *sqlmock.Rows {
+	rows := sqlmock.NewRows(taskRowColumns)
+	for _, id := range ids {
+		rows.AddRow(id, "task", id%2 == 0, createdAt)
+	}
+	return rows
+}
+
+func TestFindByID(t *testing.T) {
+	const q = `SELECT id, title, done, created_at FROM tasks WHERE id = $1;`
+	r, mock := newMockRepo(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery(q).WithArgs(7).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns).AddRow(7, "buy milk", true, createdAt))
+	got, err := r.FindByID(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Task{ID: 7, Title: "buy milk", Done: true, CreatedAt: createdAt}); *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+
+	mock.ExpectQuery(q).WithArgs(8).WillReturnRows(sqlmock.NewRows(taskRowColumns))
+	if got, err := r.FindByID(ctx, 8); !errors.Is(err, ErrNotFound) || got != nil {
+		t.Errorf("missing task: %v, %v; want ErrNotFound", got, err)
+	}
+
+	mock.ExpectQuery(q).WithArgs(9).WillReturnError(errors.New("connection reset"))
+	_, err = r.FindByID(ctx, 9)
+	if err == nil || errors.Is(err, ErrNotFound) || err.Error() != "Repo.FindByID: connection reset" {
+		t.Errorf("driver error = %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	const q = `UPDATE tasks SET title = $2, done = $3 WHERE id = $1 RETURNING id, title, done, created_at;`
+	r, mock := newMockRepo(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery(q).WithArgs(1, "renamed", true).
+		WillReturnRows(sqlmock.NewRows(taskRowColumns).AddRow(1, "renamed", true, createdAt))
+	got, err := r.Update(ctx, 1, "renamed", true)
+	if err != nil || got.Title != "renamed" || !got.Done {
+		t.Fatalf("Update = %+v, %v", got, err)
+	}
+
+	mock.ExpectQuery(q).WithArgs(2, "x", false).WillReturnRows(sqlmock.NewRows(taskRowColumns))
+	if _, err := r.Update(ctx, 2, "x", false); !errors.Is(err, ErrNotFound) || !strings.HasPrefix(err.Error(), "Repo.Update: ") {
+		t.Errorf("missing task: %v", err)
+	}
+}
+
+func TestMarkDoneAndDeleteNotFound(t *testing.T) {
+	r, mock := newMockRepo(t)
+	ctx := context.Background()
+
+	mock.ExpectExec(`UPDATE tasks SET done = $2 WHERE id = $1;`).WithArgs(1, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE tasks SET done = $2 WHERE id = $1;`).WithArgs(2, false).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM tasks WHERE id = $1;`).WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM tasks WHERE id = $1;`).WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM tasks WHERE id = $1;`).WithArgs(5).
+		WillReturnError(sql.ErrConnDone)
+
+	if err := r.MarkDone(ctx, 1, true); err != nil {
+		t.Errorf("MarkDone(1): %v", err)
+	}
+	if err := r.MarkDone(ctx, 2, false); !errors.Is(err, ErrNotFound) || err.Error() != "Repo.MarkDone: task not found" {
+		t.Errorf("MarkDone(2) = %v", err)
+	}
+	if err := r.Delete(ctx, 3); err != nil {
+		t.Errorf("Delete(3): %v", err)
+	}
+	if err := r.Delete(ctx, 4); !errors.Is(err, ErrNotFound) || err.Error() != "Repo.Delete: task not found" {
+		t.Errorf("Delete(4) = %v", err)
+	}
+	if err := r.Delete(ctx, 5); !errors.Is(err, sql.ErrConnDone) || errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete(5) = %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name      string
+		params    ListParams
+		query     string
+		args      []any
+		rows      []int
+		ids       []int
+		nextAfter int
+	}{
+		{
+			name:   "default limit",
+			params: ListParams{},
+			query:  `SELECT id, title, done, created_at FROM tasks WHERE id > $1 ORDER BY id LIMIT $2;`,
+			args:   []any{0, DefaultListLimit + 1},
+			rows:   []int{1, 2},
+			ids:    []int{1, 2},
+		},
+		{
+			name:      "more pages",
+			params:    ListParams{AfterID: 10, Limit: 2},
+			query:     `SELECT id, title, done, created_at FROM tasks WHERE id > $1 ORDER BY id LIMIT $2;`,
+			args:      []any{10, 3},
+			rows:      []int{11, 14, 15},
+			ids:       []int{11, 14},
+			nextAfter: 14,
+		},
+		{
+			name:   "done filter and limit cap",
+			params: ListParams{Done: &yes, Limit: MaxListLimit + 500},
+			query:  `SELECT id, title, done, created_at FROM tasks WHERE id > $1 AND done = $2 ORDER BY id LIMIT $3;`,
+			args:   []any{0, true, MaxListLimit + 1},
+			rows:   nil,
+			ids:    []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, mock := newMockRepo(t)
+			var args []driver.Value
+			for _, a := range tt.args {
+				args = append(args, a)
+			}
+			mock.ExpectQuery(tt.query).WithArgs(args...).WillReturnRows(taskRows(tt.rows...))
+
+			page, err := r.List(context.Background(), tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []int{}
+			for _, task := range page.Items {
+				got = append(got, task.ID)
+			}
+			if page.Items == nil || !slices.Equal(got, tt.ids) || page.NextAfterID != tt.nextAfter {
+				t.Errorf("page = %v (next %d), want %v (next %d)", got, page.NextAfterID, tt.ids, tt.nextAfter)
+			}
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	r, mock := newMockRepo(t)
+	ctx := context.Background()
+	mock.ExpectQuery(`SELECT count(*) FROM tasks;`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery(`SELECT count(*) FROM tasks WHERE done = $1;`).WithArgs(false).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	if n, err := r.Count(ctx, nil); err != nil || n != 5 {
+		t.Errorf("Count(nil) = %d, %v", n, err)
+	}
+	no := false
+	if n, err := r.Count(ctx, &no); err != nil || n != 3 {
+		t.Errorf("Count(false) = %d, %v", n, err)
+	}
+}
+
+func TestSearchByTitleEscapesPattern(t *testing.T) {
+	const q = `SELECT id, title, done, created_at FROM tasks WHERE title ILIKE $1 ESCAPE '\' ORDER BY id LIMIT $2;`
+	tests := []struct {
+		query   string
+		limit   int
+		pattern string
+		sqlLim  int
+	}{
+		{"milk", 0, `%milk%`, DefaultListLimit},
+		{"100%", 10, `%100\%%`, 10},
+		{"a_b", 5000, `%a\_b%`, MaxListLimit},
+		{`C:\tmp`, 1, `%C:\\tmp%`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r, mock := newMockRepo(t)
+			mock.ExpectQuery(q).WithArgs(tt.pattern, tt.sqlLim).WillReturnRows(taskRows(1))
+			got, err := r.SearchByTitle(context.Background(), tt.query, tt.limit)
+			if err != nil || len(got) != 1 {
+				t.Errorf("SearchByTitle = %v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestCreateManyRollsBackOnError(t *testing.T) {
+	const q = `INSERT INTO tasks (title) VALUES ($1);`
+	r, mock := newMockRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(q).WithArgs("a").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(q).WithArgs("b").WillReturnError(errors.New("value too long"))
+	mock.ExpectRollback()
+
+	err := r.CreateMany(context.Background(), []string{"a", "b", "c"})
+	if err == nil || err.Error() != "Repo.CreateMany: value too long" {
+		t.Errorf("CreateMany = %v", err)
+	}
+}
+
+// Методы передают ctx в database/sql: с отменённым контекстом запрос
+// не доходит до драйвера (ожиданий у мока нет), а ошибка сохраняет context.Canceled.
+func TestContextCancellation(t *testing.T) {
+	r, _ := newMockRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := r.ListTasks(ctx); !errors.Is(err, context.Canceled) || !strings.HasPrefix(err.Error(), "Repo.ListTasks: ") {
+		t.Errorf("ListTasks with cancelled ctx = %v", err)
+	}
+	if _, err := r.FindByID(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("FindByID with cancelled ctx = %v", err)
+	}
+	if err := r.MarkDone(ctx, 1, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("MarkDone with cancelled ctx = %v", err)
+	}
+}